@@ -0,0 +1,161 @@
+// Package bastion creates the OCI Bastion and its sessions described by the bastion section of the config
+package bastion
+
+import (
+	"fmt"
+	"infra/config"
+
+	ocibastion "github.com/pulumi/pulumi-oci/sdk/v3/go/oci/bastion"
+	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/core"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// OutputBastion is the stack export key of the bastion and its sessions
+const OutputBastion = "bastion"
+
+// Session defaults applied when the session config leaves them unset
+const (
+	DefaultUsername = "opc"
+	DefaultSSHPort  = 22
+)
+
+// BastionCfg wraps the BastionConfig and provides methods for managing the bastion
+type BastionCfg struct {
+	config.BastionConfig
+	// Subnets holds the subnets created in this program keyed by their config name
+	Subnets map[string]*core.Subnet
+	// Instances holds the instances created in this program keyed by their config name
+	Instances map[string]*core.Instance
+	// Compartments resolves compartment references by name, see config.BaseConfig.CompartmentIDInput
+	Compartments map[string]pulumi.StringInput
+}
+
+// Bastion is a created bastion with its sessions keyed by config name
+type Bastion struct {
+	Bastion  *ocibastion.Bastion
+	Sessions map[string]*ocibastion.Session
+}
+
+// CreateBastion creates the bastion targeting the configured subnet and its sessions. It returns nil
+// when the config does not describe a bastion.
+func (b *BastionCfg) CreateBastion(ctx *pulumi.Context) (*Bastion, error) {
+	if !b.Enabled() {
+		return nil, nil
+	}
+
+	subnet, ok := b.Subnets[b.Subnet]
+	if !ok || subnet == nil {
+		return nil, fmt.Errorf("subnet %s not found", b.Subnet)
+	}
+
+	compartmentID, err := b.CompartmentIDInput(b.Compartments)
+	if err != nil {
+		return nil, err
+	}
+
+	args := &ocibastion.BastionArgs{
+		BastionType:               pulumi.String("STANDARD"),
+		CompartmentId:             compartmentID,
+		Name:                      pulumi.String(b.Name),
+		TargetSubnetId:            subnet.ID().ToStringOutput(),
+		ClientCidrBlockAllowLists: pulumi.ToStringArray(b.ClientCIDRs),
+		MaxSessionTtlInSeconds:    pulumi.Int(b.maxSessionTTL()),
+	}
+
+	bastion, err := ocibastion.NewBastion(ctx, b.Name, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bastion %s: %w", b.Name, err)
+	}
+
+	sessions, err := b.createSessions(ctx, bastion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Bastion{Bastion: bastion, Sessions: sessions}, nil
+}
+
+// createSessions creates the configured sessions to instances created in this program
+func (b *BastionCfg) createSessions(ctx *pulumi.Context, bastion *ocibastion.Bastion) (map[string]*ocibastion.Session, error) {
+	sessions := make(map[string]*ocibastion.Session, len(b.Sessions))
+	for _, v := range b.Sessions {
+		instance, ok := b.Instances[v.Instance]
+		if !ok || instance == nil {
+			return nil, fmt.Errorf("session %s: instance %s not found", v.Name, v.Instance)
+		}
+
+		target, err := sessionTarget(v, instance)
+		if err != nil {
+			return nil, fmt.Errorf("session %s: %w", v.Name, err)
+		}
+
+		ttl := v.TTLSeconds
+		if ttl == 0 {
+			ttl = b.maxSessionTTL()
+		}
+
+		session, err := ocibastion.NewSession(ctx, b.Name+"-"+v.Name, &ocibastion.SessionArgs{
+			BastionId:   bastion.ID().ToStringOutput(),
+			DisplayName: pulumi.String(v.Name),
+			KeyType:     pulumi.String("PUB"),
+			KeyDetails: &ocibastion.SessionKeyDetailsArgs{
+				PublicKeyContent: pulumi.String(v.SSHPublicKey),
+			},
+			SessionTtlInSeconds:   pulumi.Int(ttl),
+			TargetResourceDetails: target,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create session %s: %w", v.Name, err)
+		}
+		sessions[v.Name] = session
+	}
+	return sessions, nil
+}
+
+// sessionTarget builds the target of a managed SSH or port forwarding session to an instance
+func sessionTarget(v config.BastionSessionConfig, instance *core.Instance) (*ocibastion.SessionTargetResourceDetailsArgs, error) {
+	port := v.Port
+	if port == 0 {
+		port = DefaultSSHPort
+	}
+
+	target := &ocibastion.SessionTargetResourceDetailsArgs{
+		TargetResourceId:   instance.ID().ToStringOutput(),
+		TargetResourcePort: pulumi.Int(port),
+	}
+	switch v.Type {
+	case config.SessionManagedSSH:
+		username := v.Username
+		if username == "" {
+			username = DefaultUsername
+		}
+		target.SessionType = pulumi.String("MANAGED_SSH")
+		target.TargetResourceOperatingSystemUserName = pulumi.String(username)
+	case config.SessionPortForwarding:
+		target.SessionType = pulumi.String("PORT_FORWARDING")
+	default:
+		return nil, fmt.Errorf("unknown session type %s", v.Type)
+	}
+	return target, nil
+}
+
+// maxSessionTTL returns the configured maximum session TTL or the OCI maximum
+func (b *BastionCfg) maxSessionTTL() int {
+	if b.MaxSessionTTLSeconds == 0 {
+		return config.MaxSessionTTLSeconds
+	}
+	return b.MaxSessionTTLSeconds
+}
+
+// Outputs returns the bastion ID and the session IDs keyed by config name
+func (b *Bastion) Outputs() pulumi.Map {
+	sessions := pulumi.StringMap{}
+	for name, session := range b.Sessions {
+		sessions[name] = session.ID().ToStringOutput()
+	}
+
+	return pulumi.Map{
+		"id":       b.Bastion.ID().ToStringOutput(),
+		"sessions": sessions,
+	}
+}
@@ -0,0 +1,170 @@
+package bastion
+
+import (
+	"infra/config"
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/core"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// BastionMocks records the inputs of every created resource by name
+type BastionMocks struct {
+	mu        sync.Mutex
+	resources map[string]resource.PropertyMap
+}
+
+func (m *BastionMocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	m.mu.Lock()
+	m.resources[args.Name] = args.Inputs
+	m.mu.Unlock()
+	return args.Name + "_id", args.Inputs, nil
+}
+
+func (m *BastionMocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+	return args.Args, nil
+}
+
+func newBastionMocks() *BastionMocks {
+	return &BastionMocks{resources: make(map[string]resource.PropertyMap)}
+}
+
+func newTestBastionCfg() BastionCfg {
+	return BastionCfg{
+		BastionConfig: config.BastionConfig{
+			BaseConfig:  config.BaseConfig{CompartmentID: "ocid1.compartment.oc1..example"},
+			Name:        "devbastion",
+			Subnet:      "private-subnet",
+			ClientCIDRs: []string{"203.0.113.0/24"},
+			Sessions: []config.BastionSessionConfig{
+				{Name: "ssh", Type: config.SessionManagedSSH, Instance: "web", SSHPublicKey: "ssh-rsa AAAA"},
+				{Name: "postgres", Type: config.SessionPortForwarding, Instance: "web", Port: 5432, SSHPublicKey: "ssh-rsa AAAA", TTLSeconds: 3600},
+			},
+		},
+	}
+}
+
+// createTestBastion creates the subnet and instance referenced by the test config, then the bastion
+func createTestBastion(ctx *pulumi.Context, bcfg *BastionCfg) (*Bastion, error) {
+	subnet, err := core.NewSubnet(ctx, "private-subnet", &core.SubnetArgs{
+		CompartmentId: pulumi.String("ocid1.compartment.oc1..example"),
+		VcnId:         pulumi.String("vcn_id"),
+		CidrBlock:     pulumi.String("10.0.2.0/24"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	instance, err := core.NewInstance(ctx, "web", &core.InstanceArgs{
+		CompartmentId:      pulumi.String("ocid1.compartment.oc1..example"),
+		AvailabilityDomain: pulumi.String("ad-1"),
+		Shape:              pulumi.String("VM.Standard.E4.Flex"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bcfg.Subnets = map[string]*core.Subnet{"private-subnet": subnet}
+	bcfg.Instances = map[string]*core.Instance{"web": instance}
+	return bcfg.CreateBastion(ctx)
+}
+
+func TestCreateBastion(t *testing.T) {
+	bcfg := newTestBastionCfg()
+	mocks := newBastionMocks()
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		bastion, err := createTestBastion(ctx, &bcfg)
+		if err != nil {
+			return err
+		}
+		if bastion == nil || len(bastion.Sessions) != 2 {
+			t.Fatalf("Expected a bastion with 2 sessions, but got %v", bastion)
+		}
+		if outputs := bastion.Outputs(); outputs["id"] == nil || outputs["sessions"] == nil {
+			t.Errorf("Expected id and sessions outputs, but got %v", outputs)
+		}
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	inputs := mocks.resources["devbastion"]
+	if got := inputs["targetSubnetId"].StringValue(); got != "private-subnet_id" {
+		t.Errorf("Expected target subnet private-subnet_id, but got %s", got)
+	}
+	if got := inputs["maxSessionTtlInSeconds"].NumberValue(); got != config.MaxSessionTTLSeconds {
+		t.Errorf("Expected max session TTL %d, but got %v", config.MaxSessionTTLSeconds, got)
+	}
+	if cidrs := inputs["clientCidrBlockAllowLists"].ArrayValue(); len(cidrs) != 1 || cidrs[0].StringValue() != "203.0.113.0/24" {
+		t.Errorf("Expected client CIDR 203.0.113.0/24, but got %v", cidrs)
+	}
+
+	tests := []struct {
+		session  string
+		ttl      float64
+		expected map[string]interface{}
+	}{
+		{"devbastion-ssh", config.MaxSessionTTLSeconds, map[string]interface{}{
+			"sessionType":                           "MANAGED_SSH",
+			"targetResourceId":                      "web_id",
+			"targetResourcePort":                    float64(DefaultSSHPort),
+			"targetResourceOperatingSystemUserName": DefaultUsername,
+		}},
+		{"devbastion-postgres", 3600, map[string]interface{}{
+			"sessionType":        "PORT_FORWARDING",
+			"targetResourceId":   "web_id",
+			"targetResourcePort": float64(5432),
+		}},
+	}
+	for _, tt := range tests {
+		session, ok := mocks.resources[tt.session]
+		if !ok {
+			t.Errorf("Expected session %s to be created", tt.session)
+			continue
+		}
+		if got := session["sessionTtlInSeconds"].NumberValue(); got != tt.ttl {
+			t.Errorf("Expected %s TTL %v, but got %v", tt.session, tt.ttl, got)
+		}
+		target := session["targetResourceDetails"].ObjectValue().Mappable()
+		for key, value := range tt.expected {
+			if target[key] != value {
+				t.Errorf("Expected %s %s to be %v, but got %v", tt.session, key, value, target[key])
+			}
+		}
+		if _, ok := target["targetResourceOperatingSystemUserName"]; ok && tt.expected["sessionType"] == "PORT_FORWARDING" {
+			t.Errorf("Expected no username for %s", tt.session)
+		}
+	}
+}
+
+func TestCreateBastionDisabled(t *testing.T) {
+	bcfg := BastionCfg{}
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		bastion, err := bcfg.CreateBastion(ctx)
+		if bastion != nil {
+			t.Errorf("Expected no bastion, but got %v", bastion)
+		}
+		return err
+	}, pulumi.WithMocks("project", "stack", newBastionMocks()))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestCreateBastionUnknownInstance(t *testing.T) {
+	bcfg := newTestBastionCfg()
+	bcfg.Sessions[0].Instance = "missing"
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		_, err := createTestBastion(ctx, &bcfg)
+		return err
+	}, pulumi.WithMocks("project", "stack", newBastionMocks()))
+
+	if err == nil {
+		t.Errorf("Expected error but got none")
+	}
+}
@@ -1,8 +1,10 @@
 package compute
 
 import (
+	"encoding/base64"
 	"fmt"
 	"infra/config"
+	"strconv"
 
 	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/core"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
@@ -11,12 +13,37 @@ import (
 // ComputeCfg wraps the ComputeConfig and provides methods for managing compute resources
 type ComputeCfg struct {
 	config.ComputeConfig
+	// Subnets holds the subnets created in this program keyed by their config name,
+	// so that instances can reference them with subnet instead of a literal subnet_id
+	Subnets map[string]*core.Subnet
+	// NSGs holds the network security groups created in this program keyed by display name
+	NSGs map[string]*core.NetworkSecurityGroup
+	// Compartments holds the IDs of the compartments created in this program keyed by name,
+	// so that instances can reference their compartment with compartment
+	Compartments map[string]pulumi.StringInput
+	// AvailabilityDomains holds the availability domain names of the region sorted by name,
+	// see identity.AvailabilityDomainNames
+	AvailabilityDomains pulumi.StringArrayInput
+	// StackOutputs holds the stack outputs keyed by export key that user data templates can look up
+	StackOutputs pulumi.Map
+
+	// namePrefix and resourceOptions are set by NewFleet to namespace and parent every instance
+	namePrefix      string
+	resourceOptions []pulumi.ResourceOption
+}
+
+// resourceName returns the Pulumi resource name of a child resource, prefixed with the component name if any
+func (c *ComputeCfg) resourceName(name string) string {
+	if c.namePrefix == "" {
+		return name
+	}
+	return c.namePrefix + "-" + name
 }
 
 // ValidateConfig validates the compute configuration
 func (c *ComputeCfg) ValidateConfig() error {
-	if c.CompartmentID == "" {
-		return fmt.Errorf("compartment_id is required")
+	if c.CompartmentID == "" && c.Compartment == "" {
+		return fmt.Errorf("compartment_id or compartment is required")
 	}
 
 	if len(c.Instances) == 0 {
@@ -30,11 +57,14 @@ func (c *ComputeCfg) ValidateConfig() error {
 		if instance.Shape == "" {
 			return fmt.Errorf("instance[%d]: shape is required", i)
 		}
-		if instance.SubnetID == "" {
-			return fmt.Errorf("instance[%d]: subnet_id is required", i)
+		if instance.Subnet == "" && instance.SubnetID == "" {
+			return fmt.Errorf("instance[%d]: subnet or subnet_id is required", i)
+		}
+		if instance.Subnet != "" && instance.SubnetID != "" {
+			return fmt.Errorf("instance[%d]: only one of subnet or subnet_id may be set", i)
 		}
-		if instance.ImageOCID == "" {
-			return fmt.Errorf("instance[%d]: image_ocid is required", i)
+		if instance.ImageOCID == "" && instance.Image == nil {
+			return fmt.Errorf("instance[%d]: image_ocid or image is required", i)
 		}
 		if instance.SSHPublicKey == "" {
 			return fmt.Errorf("instance[%d]: ssh_public_key is required", i)
@@ -56,32 +86,72 @@ func (c *ComputeCfg) CreateInstance(ctx *pulumi.Context, instanceIndex int) (*co
 
 	instance := c.Instances[instanceIndex]
 
+	subnetID, err := c.SubnetIDFor(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	nsgIDs, err := c.NSGIDsFor(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	compartmentID, err := c.CompartmentIDInput(c.Compartments)
+	if err != nil {
+		return nil, err
+	}
+
+	imageID, err := c.ImageIDFor(ctx, instance, compartmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	availabilityDomain, faultDomain, err := c.PlacementFor(instance, instanceIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	vnicDetails := &core.InstanceCreateVnicDetailsArgs{
+		SubnetId: subnetID,
+		NsgIds:   nsgIDs,
+	}
+	if instance.AssignPublicIP != nil {
+		vnicDetails.AssignPublicIp = pulumi.String(strconv.FormatBool(*instance.AssignPublicIP))
+	}
+
+	sourceDetails := &core.InstanceSourceDetailsArgs{
+		SourceType: pulumi.String("image"),
+		SourceId:   imageID,
+	}
+	sourceDetails.BootVolumeSizeInGbs, sourceDetails.BootVolumeVpusPerGb = bootVolume(instance)
+
 	instanceArgs := &core.InstanceArgs{
-		CompartmentId:      pulumi.String(c.CompartmentID),
+		CompartmentId:      compartmentID,
 		Shape:              pulumi.String(instance.Shape),
-		AvailabilityDomain: pulumi.String("ad-1"),
-		SourceDetails: &core.InstanceSourceDetailsArgs{
-			SourceType: pulumi.String("image"),
-			SourceId:   pulumi.String(instance.ImageOCID),
-		},
-		CreateVnicDetails: &core.InstanceCreateVnicDetailsArgs{
-			SubnetId: pulumi.String(instance.SubnetID),
-		},
-		Metadata: pulumi.StringMap{
-			"ssh_authorized_keys": pulumi.String(instance.SSHPublicKey),
-		},
+		AvailabilityDomain: availabilityDomain,
+		FaultDomain:        faultDomain,
+		SourceDetails:      sourceDetails,
+		CreateVnicDetails:  vnicDetails,
+		Metadata:           c.MetadataFor(instance),
 	}
 
 	// Build ShapeConfig if OCPUCount or MemoryGB is specified
-	if (instance.OCPUCount != nil && *instance.OCPUCount > 0) || (instance.MemoryGB != nil && *instance.MemoryGB > 0) {
-		shapeConfig := &core.InstanceShapeConfigArgs{}
-		if instance.OCPUCount != nil && *instance.OCPUCount > 0 {
-			shapeConfig.Ocpus = pulumi.Float64(*instance.OCPUCount)
+	if ocpus, memoryGB := shapeResources(instance); ocpus != nil || memoryGB != nil {
+		instanceArgs.ShapeConfig = &core.InstanceShapeConfigArgs{
+			Ocpus:       ocpus,
+			MemoryInGbs: memoryGB,
 		}
-		if instance.MemoryGB != nil && *instance.MemoryGB > 0 {
-			shapeConfig.MemoryInGbs = pulumi.Float64(*instance.MemoryGB)
+	}
+
+	if instance.BastionPlugin {
+		instanceArgs.AgentConfig = &core.InstanceAgentConfigArgs{
+			PluginsConfigs: core.InstanceAgentConfigPluginsConfigArray{
+				core.InstanceAgentConfigPluginsConfigArgs{
+					Name:         pulumi.String(bastionPlugin),
+					DesiredState: pulumi.String("ENABLED"),
+				},
+			},
 		}
-		instanceArgs.ShapeConfig = shapeConfig
 	}
 
 	displayName := instance.DisplayName
@@ -90,7 +160,137 @@ func (c *ComputeCfg) CreateInstance(ctx *pulumi.Context, instanceIndex int) (*co
 	}
 	instanceArgs.DisplayName = pulumi.String(displayName)
 
-	return core.NewInstance(ctx, instance.Name, instanceArgs)
+	return core.NewInstance(ctx, c.resourceName(instance.Name), instanceArgs, c.resourceOptions...)
+}
+
+// bastionPlugin is the Oracle Cloud Agent plugin required by managed SSH sessions of the Bastion service
+const bastionPlugin = "Bastion"
+
+// MetadataFor returns the metadata of an instance: the SSH key and the user data passed to cloud-init
+func (c *ComputeCfg) MetadataFor(instance config.InstanceConfig) pulumi.StringMap {
+	metadata := pulumi.StringMap{
+		"ssh_authorized_keys": pulumi.String(instance.SSHPublicKey),
+	}
+	if instance.UserData != nil {
+		metadata["user_data"] = c.UserDataFor(instance)
+	}
+	return metadata
+}
+
+// shapeResources returns the OCPUs and memory of a flexible shape, nil when left to the shape defaults
+func shapeResources(instance config.InstanceConfig) (ocpus, memoryGB pulumi.Float64PtrInput) {
+	if instance.OCPUCount != nil && *instance.OCPUCount > 0 {
+		ocpus = pulumi.Float64(*instance.OCPUCount)
+	}
+	if instance.MemoryGB != nil && *instance.MemoryGB > 0 {
+		memoryGB = pulumi.Float64(*instance.MemoryGB)
+	}
+	return ocpus, memoryGB
+}
+
+// bootVolume returns the boot volume size and VPUs per GB, nil when left to the OCI defaults
+func bootVolume(instance config.InstanceConfig) (sizeGB, vpus pulumi.StringPtrInput) {
+	if instance.BootVolumeSizeGB != 0 {
+		sizeGB = pulumi.String(strconv.Itoa(instance.BootVolumeSizeGB))
+	}
+	if instance.BootVolumeVPUs != nil {
+		vpus = pulumi.String(strconv.Itoa(*instance.BootVolumeVPUs))
+	}
+	return sizeGB, vpus
+}
+
+// SubnetIDFor resolves the subnet of an instance, either from a subnet created in this program
+// referenced by name or from an explicit OCID for a pre-existing subnet
+func (c *ComputeCfg) SubnetIDFor(instance config.InstanceConfig) (pulumi.StringInput, error) {
+	if instance.Subnet != "" {
+		subnet, ok := c.Subnets[instance.Subnet]
+		if !ok || subnet == nil {
+			return nil, fmt.Errorf("subnet %s not found", instance.Subnet)
+		}
+		return subnet.ID().ToStringOutput(), nil
+	}
+
+	if instance.SubnetID == "" {
+		return nil, fmt.Errorf("instance %s: subnet or subnet_id is required", instance.Name)
+	}
+	return pulumi.String(instance.SubnetID), nil
+}
+
+// UserDataFor returns the base64 encoded user data of an instance, rendering templates once the
+// stack outputs they look up are known
+func (c *ComputeCfg) UserDataFor(instance config.InstanceConfig) pulumi.StringInput {
+	userData := *instance.UserData
+	if !userData.Template {
+		return pulumi.String(base64.StdEncoding.EncodeToString([]byte(userData.Content())))
+	}
+
+	return c.StackOutputs.ToMapOutput().ApplyT(func(outputs map[string]interface{}) (string, error) {
+		rendered, err := userData.Render(outputs)
+		if err != nil {
+			return "", fmt.Errorf("instance %s user_data: %w", instance.Name, err)
+		}
+		return base64.StdEncoding.EncodeToString([]byte(rendered)), nil
+	}).(pulumi.StringOutput)
+}
+
+// PlacementFor resolves the availability domain and fault domain of the instance at index once the
+// availability domains of the region are known, see PlaceInstance
+func (c *ComputeCfg) PlacementFor(instance config.InstanceConfig, index int) (pulumi.StringInput, pulumi.StringPtrInput, error) {
+	if c.AvailabilityDomains == nil {
+		// Without the region's availability domains only an explicit name can be used
+		placement, err := PlaceInstance(instance, index, c.Spread, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("instance %s: %w", instance.Name, err)
+		}
+		return pulumi.String(placement.AvailabilityDomain), optionalString(placement.FaultDomain), nil
+	}
+
+	availabilityDomains := c.AvailabilityDomains.ToStringArrayOutput()
+	place := func(availabilityDomains []string) (Placement, error) {
+		placement, err := PlaceInstance(instance, index, c.Spread, availabilityDomains)
+		if err != nil {
+			return placement, fmt.Errorf("instance %s: %w", instance.Name, err)
+		}
+		return placement, nil
+	}
+	availabilityDomain := availabilityDomains.ApplyT(func(availabilityDomains []string) (string, error) {
+		placement, err := place(availabilityDomains)
+		return placement.AvailabilityDomain, err
+	}).(pulumi.StringOutput)
+	faultDomain := availabilityDomains.ApplyT(func(availabilityDomains []string) (*string, error) {
+		placement, err := place(availabilityDomains)
+		if err != nil || placement.FaultDomain == "" {
+			return nil, err
+		}
+		return &placement.FaultDomain, nil
+	}).(pulumi.StringPtrOutput)
+	return availabilityDomain, faultDomain, nil
+}
+
+// optionalString returns nil for an empty string so that OCI applies its default
+func optionalString(s string) pulumi.StringPtrInput {
+	if s == "" {
+		return nil
+	}
+	return pulumi.String(s)
+}
+
+// NSGIDsFor resolves the network security groups attached to the VNIC of an instance
+func (c *ComputeCfg) NSGIDsFor(instance config.InstanceConfig) (pulumi.StringArrayInput, error) {
+	if len(instance.NSGs) == 0 {
+		return nil, nil
+	}
+
+	nsgIDs := make(pulumi.StringArray, 0, len(instance.NSGs))
+	for _, name := range instance.NSGs {
+		nsg, ok := c.NSGs[name]
+		if !ok || nsg == nil {
+			return nil, fmt.Errorf("network security group %s not found", name)
+		}
+		nsgIDs = append(nsgIDs, nsg.ID().ToStringOutput())
+	}
+
+	return nsgIDs, nil
 }
 
 // CreateAllInstances creates all instances defined in the configuration
@@ -122,7 +322,8 @@ func (c *ComputeCfg) GetInstance(name string) (*config.InstanceConfig, error) {
 	return nil, fmt.Errorf("instance %s not found", name)
 }
 
-// CreateInstancesInSubnet creates all instances that belong to a specific subnet
+// CreateInstancesInSubnet creates all instances that belong to a specific subnet,
+// matched either by subnet name or by subnet OCID
 func (c *ComputeCfg) CreateInstancesInSubnet(ctx *pulumi.Context, subnetID string) ([]*core.Instance, error) {
 	if len(c.Instances) == 0 {
 		return nil, fmt.Errorf("at least one instance must be defined")
@@ -131,7 +332,7 @@ func (c *ComputeCfg) CreateInstancesInSubnet(ctx *pulumi.Context, subnetID strin
 	var instances []*core.Instance
 
 	for i, instance := range c.Instances {
-		if instance.SubnetID == subnetID {
+		if instance.SubnetID == subnetID || instance.Subnet == subnetID {
 			createdInstance, err := c.CreateInstance(ctx, i)
 			if err != nil {
 				return nil, fmt.Errorf("failed to create instance %s in subnet %s: %w", instance.Name, subnetID, err)
@@ -1,8 +1,10 @@
 package compute
 
 import (
+	"encoding/base64"
 	"infra/config"
 
+	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/core"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 	"testing"
@@ -15,6 +17,9 @@ func (ComputeMocks) NewResource(args pulumi.MockResourceArgs) (string, resource.
 }
 
 func (ComputeMocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+	if args.Token == "oci:Core/getImages:getImages" {
+		return testImagesResult(args.Args), nil
+	}
 	return args.Args, nil
 }
 
@@ -27,9 +32,13 @@ const (
 	testSSHPublicKey  = "ssh-rsa AAAAB3NzaC1yc2E..."
 )
 
+// testAvailabilityDomains stands in for the availability domains looked up by identity.AvailabilityDomainNames
+var testAvailabilityDomains = pulumi.ToStringArray([]string{"Uocm:PHX-AD-1", "Uocm:PHX-AD-2", "Uocm:PHX-AD-3"})
+
 // newTestComputeCfg creates a test ComputeCfg with the specified instances
 func newTestComputeCfg(compartmentID string, instances []config.InstanceConfig) ComputeCfg {
 	return ComputeCfg{
+		AvailabilityDomains: testAvailabilityDomains,
 		ComputeConfig: config.ComputeConfig{
 			BaseConfig: config.BaseConfig{
 				CompartmentID: compartmentID,
@@ -59,6 +68,7 @@ func TestValidateConfig(t *testing.T) {
 		{
 			name: "Valid configuration",
 			computeCfg: ComputeCfg{
+				AvailabilityDomains: testAvailabilityDomains,
 				ComputeConfig: config.ComputeConfig{
 					BaseConfig: config.BaseConfig{
 						CompartmentID: "compartment-123",
@@ -79,6 +89,7 @@ func TestValidateConfig(t *testing.T) {
 		{
 			name: "Empty compartment ID",
 			computeCfg: ComputeCfg{
+				AvailabilityDomains: testAvailabilityDomains,
 				ComputeConfig: config.ComputeConfig{
 					BaseConfig: config.BaseConfig{
 						CompartmentID: "",
@@ -99,6 +110,7 @@ func TestValidateConfig(t *testing.T) {
 		{
 			name: "No instances defined",
 			computeCfg: ComputeCfg{
+				AvailabilityDomains: testAvailabilityDomains,
 				ComputeConfig: config.ComputeConfig{
 					BaseConfig: config.BaseConfig{
 						CompartmentID: "compartment-123",
@@ -111,6 +123,7 @@ func TestValidateConfig(t *testing.T) {
 		{
 			name: "Instance missing name",
 			computeCfg: ComputeCfg{
+				AvailabilityDomains: testAvailabilityDomains,
 				ComputeConfig: config.ComputeConfig{
 					BaseConfig: config.BaseConfig{
 						CompartmentID: "compartment-123",
@@ -130,6 +143,7 @@ func TestValidateConfig(t *testing.T) {
 		{
 			name: "Instance missing shape",
 			computeCfg: ComputeCfg{
+				AvailabilityDomains: testAvailabilityDomains,
 				ComputeConfig: config.ComputeConfig{
 					BaseConfig: config.BaseConfig{
 						CompartmentID: "compartment-123",
@@ -149,6 +163,7 @@ func TestValidateConfig(t *testing.T) {
 		{
 			name: "Instance missing subnet ID",
 			computeCfg: ComputeCfg{
+				AvailabilityDomains: testAvailabilityDomains,
 				ComputeConfig: config.ComputeConfig{
 					BaseConfig: config.BaseConfig{
 						CompartmentID: "compartment-123",
@@ -168,6 +183,7 @@ func TestValidateConfig(t *testing.T) {
 		{
 			name: "Instance missing image OCID",
 			computeCfg: ComputeCfg{
+				AvailabilityDomains: testAvailabilityDomains,
 				ComputeConfig: config.ComputeConfig{
 					BaseConfig: config.BaseConfig{
 						CompartmentID: "compartment-123",
@@ -187,6 +203,7 @@ func TestValidateConfig(t *testing.T) {
 		{
 			name: "Instance missing SSH public key",
 			computeCfg: ComputeCfg{
+				AvailabilityDomains: testAvailabilityDomains,
 				ComputeConfig: config.ComputeConfig{
 					BaseConfig: config.BaseConfig{
 						CompartmentID: "compartment-123",
@@ -206,6 +223,7 @@ func TestValidateConfig(t *testing.T) {
 		{
 			name: "Multiple valid instances",
 			computeCfg: ComputeCfg{
+				AvailabilityDomains: testAvailabilityDomains,
 				ComputeConfig: config.ComputeConfig{
 					BaseConfig: config.BaseConfig{
 						CompartmentID: "compartment-123",
@@ -255,6 +273,7 @@ func TestCreateInstance(t *testing.T) {
 		{
 			name: "Create instance with index 0",
 			computeCfg: ComputeCfg{
+				AvailabilityDomains: testAvailabilityDomains,
 				ComputeConfig: config.ComputeConfig{
 					BaseConfig: config.BaseConfig{
 						CompartmentID: "compartment-123",
@@ -285,6 +304,7 @@ func TestCreateInstance(t *testing.T) {
 		{
 			name: "Create instance with index 1",
 			computeCfg: ComputeCfg{
+				AvailabilityDomains: testAvailabilityDomains,
 				ComputeConfig: config.ComputeConfig{
 					BaseConfig: config.BaseConfig{
 						CompartmentID: "compartment-123",
@@ -339,6 +359,7 @@ func TestCreateInstance(t *testing.T) {
 
 func TestCreateInstanceWithInvalidIndex(t *testing.T) {
 	computeCfg := ComputeCfg{
+		AvailabilityDomains: testAvailabilityDomains,
 		ComputeConfig: config.ComputeConfig{
 			BaseConfig: config.BaseConfig{
 				CompartmentID: "compartment-123",
@@ -384,6 +405,7 @@ func TestCreateInstanceWithShapeConfig(t *testing.T) {
 		{
 			name: "Instance with OCPUCount only",
 			computeCfg: ComputeCfg{
+				AvailabilityDomains: testAvailabilityDomains,
 				ComputeConfig: config.ComputeConfig{
 					BaseConfig: config.BaseConfig{
 						CompartmentID: "compartment-123",
@@ -406,6 +428,7 @@ func TestCreateInstanceWithShapeConfig(t *testing.T) {
 		{
 			name: "Instance with MemoryGB only",
 			computeCfg: ComputeCfg{
+				AvailabilityDomains: testAvailabilityDomains,
 				ComputeConfig: config.ComputeConfig{
 					BaseConfig: config.BaseConfig{
 						CompartmentID: "compartment-123",
@@ -428,6 +451,7 @@ func TestCreateInstanceWithShapeConfig(t *testing.T) {
 		{
 			name: "Instance with both OCPUCount and MemoryGB",
 			computeCfg: ComputeCfg{
+				AvailabilityDomains: testAvailabilityDomains,
 				ComputeConfig: config.ComputeConfig{
 					BaseConfig: config.BaseConfig{
 						CompartmentID: "compartment-123",
@@ -451,6 +475,7 @@ func TestCreateInstanceWithShapeConfig(t *testing.T) {
 		{
 			name: "Instance without OCPUCount or MemoryGB",
 			computeCfg: ComputeCfg{
+				AvailabilityDomains: testAvailabilityDomains,
 				ComputeConfig: config.ComputeConfig{
 					BaseConfig: config.BaseConfig{
 						CompartmentID: "compartment-123",
@@ -498,6 +523,7 @@ func TestCreateInstanceWithShapeConfig(t *testing.T) {
 
 func TestCreateAllInstances(t *testing.T) {
 	computeCfg := ComputeCfg{
+		AvailabilityDomains: testAvailabilityDomains,
 		ComputeConfig: config.ComputeConfig{
 			BaseConfig: config.BaseConfig{
 				CompartmentID: "compartment-123",
@@ -554,6 +580,7 @@ func TestCreateAllInstances(t *testing.T) {
 
 func TestCreateAllInstancesWithEmptySlice(t *testing.T) {
 	computeCfg := ComputeCfg{
+		AvailabilityDomains: testAvailabilityDomains,
 		ComputeConfig: config.ComputeConfig{
 			BaseConfig: config.BaseConfig{
 				CompartmentID: "compartment-123",
@@ -570,6 +597,7 @@ func TestCreateAllInstancesWithEmptySlice(t *testing.T) {
 
 func TestCreateInstanceWithNilContext(t *testing.T) {
 	computeCfg := ComputeCfg{
+		AvailabilityDomains: testAvailabilityDomains,
 		ComputeConfig: config.ComputeConfig{
 			BaseConfig: config.BaseConfig{
 				CompartmentID: "compartment-123",
@@ -594,6 +622,7 @@ func TestCreateInstanceWithNilContext(t *testing.T) {
 
 func TestGetInstance(t *testing.T) {
 	computeCfg := ComputeCfg{
+		AvailabilityDomains: testAvailabilityDomains,
 		ComputeConfig: config.ComputeConfig{
 			BaseConfig: config.BaseConfig{
 				CompartmentID: "compartment-123",
@@ -657,6 +686,7 @@ func TestGetInstance(t *testing.T) {
 
 func TestCreateInstancesInSubnet(t *testing.T) {
 	computeCfg := ComputeCfg{
+		AvailabilityDomains: testAvailabilityDomains,
 		ComputeConfig: config.ComputeConfig{
 			BaseConfig: config.BaseConfig{
 				CompartmentID: "compartment-123",
@@ -713,6 +743,7 @@ func TestCreateInstancesInSubnet(t *testing.T) {
 
 func TestCreateInstancesInSubnetNoMatches(t *testing.T) {
 	computeCfg := ComputeCfg{
+		AvailabilityDomains: testAvailabilityDomains,
 		ComputeConfig: config.ComputeConfig{
 			BaseConfig: config.BaseConfig{
 				CompartmentID: "compartment-123",
@@ -830,6 +861,7 @@ func BenchmarkCreateInstance(b *testing.B) {
 
 func TestCreateInstancesInSubnetWithEmptySlice(t *testing.T) {
 	computeCfg := ComputeCfg{
+		AvailabilityDomains: testAvailabilityDomains,
 		ComputeConfig: config.ComputeConfig{
 			BaseConfig: config.BaseConfig{
 				CompartmentID: "compartment-123",
@@ -846,6 +878,7 @@ func TestCreateInstancesInSubnetWithEmptySlice(t *testing.T) {
 
 func TestCreateAllInstancesWithDuplicateNames(t *testing.T) {
 	computeCfg := ComputeCfg{
+		AvailabilityDomains: testAvailabilityDomains,
 		ComputeConfig: config.ComputeConfig{
 			BaseConfig: config.BaseConfig{
 				CompartmentID: "compartment-123",
@@ -886,3 +919,270 @@ func TestCreateAllInstancesWithDuplicateNames(t *testing.T) {
 		t.Errorf("Unexpected error creating instances with duplicate names: %v", err)
 	}
 }
+
+func TestCreateInstanceWithSubnetName(t *testing.T) {
+	instance := newTestInstance("instance-1")
+	instance.SubnetID = ""
+	instance.Subnet = "private-subnet"
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		subnet, err := core.NewSubnet(ctx, "private-subnet", &core.SubnetArgs{
+			CompartmentId: pulumi.String(testCompartmentID),
+			CidrBlock:     pulumi.String("10.0.2.0/24"),
+			VcnId:         pulumi.String("vcn-123"),
+		})
+		if err != nil {
+			return err
+		}
+
+		computeCfg := newTestComputeCfg(testCompartmentID, []config.InstanceConfig{instance})
+		computeCfg.Subnets = map[string]*core.Subnet{"private-subnet": subnet}
+
+		created, err := computeCfg.CreateInstance(ctx, 0)
+		if err != nil {
+			return err
+		}
+
+		pulumi.All(created.CreateVnicDetails.SubnetId(), subnet.ID()).ApplyT(func(args []interface{}) error {
+			if *args[0].(*string) != string(args[1].(pulumi.ID)) {
+				t.Errorf("Expected instance subnet %s, but got %s", args[1], *args[0].(*string))
+			}
+			return nil
+		})
+
+		return nil
+	}, pulumi.WithMocks("project", "stack", ComputeMocks(0)))
+
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestSubnetIDFor(t *testing.T) {
+	tests := []struct {
+		name          string
+		subnet        string
+		subnetID      string
+		expectedError bool
+	}{
+		{"Explicit subnet OCID", "", testSubnetID, false},
+		{"Unknown subnet name", "missing-subnet", "", true},
+		{"No subnet at all", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := newTestInstance("instance-1")
+			instance.Subnet = tt.subnet
+			instance.SubnetID = tt.subnetID
+
+			computeCfg := newTestComputeCfg(testCompartmentID, []config.InstanceConfig{instance})
+			_, err := computeCfg.SubnetIDFor(instance)
+			if tt.expectedError && err == nil {
+				t.Errorf("Expected error but got none")
+			}
+			if !tt.expectedError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNSGIDsFor(t *testing.T) {
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		nsg, err := core.NewNetworkSecurityGroup(ctx, "app-nsg", &core.NetworkSecurityGroupArgs{
+			CompartmentId: pulumi.String(testCompartmentID),
+			VcnId:         pulumi.String("vcn-123"),
+		})
+		if err != nil {
+			return err
+		}
+
+		instance := newTestInstance("instance-1")
+		instance.NSGs = []string{"app-nsg"}
+		computeCfg := newTestComputeCfg(testCompartmentID, []config.InstanceConfig{instance})
+		computeCfg.NSGs = map[string]*core.NetworkSecurityGroup{"app-nsg": nsg}
+
+		if _, err := computeCfg.CreateInstance(ctx, 0); err != nil {
+			return err
+		}
+
+		instance.NSGs = []string{"web-nsg"}
+		if _, err := computeCfg.NSGIDsFor(instance); err == nil {
+			t.Error("Expected error for unknown network security group, but got none")
+		}
+
+		return nil
+	}, pulumi.WithMocks("project", "stack", ComputeMocks(0)))
+
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestCreateInstanceWithPublicIP(t *testing.T) {
+	assignPublicIP := false
+	instance := newTestInstance("instance-1")
+	instance.AssignPublicIP = &assignPublicIP
+	computeCfg := newTestComputeCfg(testCompartmentID, []config.InstanceConfig{instance})
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		created, err := computeCfg.CreateInstance(ctx, 0)
+		if err != nil {
+			return err
+		}
+
+		created.CreateVnicDetails.AssignPublicIp().ApplyT(func(v *string) error {
+			if v == nil || *v != "false" {
+				t.Errorf("Expected assign_public_ip false, but got %v", v)
+			}
+			return nil
+		})
+
+		return nil
+	}, pulumi.WithMocks("project", "stack", ComputeMocks(0)))
+
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestCreateInstanceWithBastionPlugin(t *testing.T) {
+	instance := newTestInstance("instance-1")
+	instance.BastionPlugin = true
+	computeCfg := newTestComputeCfg(testCompartmentID, []config.InstanceConfig{instance})
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		created, err := computeCfg.CreateInstance(ctx, 0)
+		if err != nil {
+			return err
+		}
+
+		created.AgentConfig.PluginsConfigs().ApplyT(func(plugins []core.InstanceAgentConfigPluginsConfig) error {
+			if len(plugins) != 1 || plugins[0].Name != "Bastion" || plugins[0].DesiredState != "ENABLED" {
+				t.Errorf("Expected the Bastion plugin to be enabled, but got %v", plugins)
+			}
+			return nil
+		})
+
+		return nil
+	}, pulumi.WithMocks("project", "stack", ComputeMocks(0)))
+
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestCreateInstanceWithSpread(t *testing.T) {
+	var instances []config.InstanceConfig
+	for _, name := range []string{"instance-1", "instance-2", "instance-3", "instance-4"} {
+		instances = append(instances, newTestInstance(name))
+	}
+	instances[3].AvailabilityDomain = "2"
+	instances[3].FaultDomain = "3"
+	computeCfg := newTestComputeCfg(testCompartmentID, instances)
+	computeCfg.Spread = config.SpreadFaultDomains
+
+	expected := []Placement{
+		{"Uocm:PHX-AD-1", "FAULT-DOMAIN-1"},
+		{"Uocm:PHX-AD-1", "FAULT-DOMAIN-2"},
+		{"Uocm:PHX-AD-1", "FAULT-DOMAIN-3"},
+		{"Uocm:PHX-AD-2", "FAULT-DOMAIN-3"},
+	}
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		created, err := computeCfg.CreateAllInstances(ctx)
+		if err != nil {
+			return err
+		}
+
+		for i, instance := range created {
+			want := expected[i]
+			pulumi.All(instance.AvailabilityDomain, instance.FaultDomain).ApplyT(func(args []interface{}) error {
+				got := Placement{AvailabilityDomain: args[0].(string), FaultDomain: args[1].(string)}
+				if got != want {
+					t.Errorf("Expected placement %v, but got %v", want, got)
+				}
+				return nil
+			})
+		}
+
+		return nil
+	}, pulumi.WithMocks("project", "stack", ComputeMocks(0)))
+
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestCreateInstanceWithoutAvailabilityDomains(t *testing.T) {
+	instance := newTestInstance("instance-1")
+	computeCfg := newTestComputeCfg(testCompartmentID, []config.InstanceConfig{instance})
+	computeCfg.AvailabilityDomains = nil
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		_, err := computeCfg.CreateInstance(ctx, 0)
+		return err
+	}, pulumi.WithMocks("project", "stack", ComputeMocks(0)))
+
+	if err == nil {
+		t.Errorf("Expected error but got none")
+	}
+}
+
+func TestCreateInstanceWithUserData(t *testing.T) {
+	tests := []struct {
+		name     string
+		userData config.UserDataConfig
+		expected string
+	}{
+		{
+			name:     "Inline",
+			userData: config.UserDataConfig{Inline: "#cloud-config\npackages: [git]\n"},
+			expected: "#cloud-config\npackages: [git]\n",
+		},
+		{
+			name:     "Template",
+			userData: config.UserDataConfig{Inline: `DB_HOST={{ output "heatwave.host" }}`, Template: true},
+			expected: "DB_HOST=10.0.3.10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := newTestInstance("instance-1")
+			userData := tt.userData
+			instance.UserData = &userData
+			computeCfg := newTestComputeCfg(testCompartmentID, []config.InstanceConfig{instance})
+			computeCfg.StackOutputs = pulumi.Map{
+				"heatwave": pulumi.Map{"host": pulumi.String("10.0.3.10")},
+			}
+
+			err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+				created, err := computeCfg.CreateInstance(ctx, 0)
+				if err != nil {
+					return err
+				}
+
+				created.Metadata.ApplyT(func(metadata map[string]string) error {
+					decoded, err := base64.StdEncoding.DecodeString(metadata["user_data"])
+					if err != nil {
+						t.Errorf("Expected base64 user_data, but got %q", metadata["user_data"])
+					}
+					if string(decoded) != tt.expected {
+						t.Errorf("Expected user_data %q, but got %q", tt.expected, decoded)
+					}
+					if metadata["ssh_authorized_keys"] != testSSHPublicKey {
+						t.Errorf("Expected ssh_authorized_keys %s, but got %s", testSSHPublicKey, metadata["ssh_authorized_keys"])
+					}
+					return nil
+				})
+				return nil
+			}, pulumi.WithMocks("project", "stack", ComputeMocks(0)))
+
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package compute
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/core"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// FleetComponentType is the Pulumi type token of the Fleet component
+const FleetComponentType = "infra:compute:Fleet"
+
+// OutputInstances and OutputPools are the output keys of the instances and instance pools of a Fleet,
+// also used as stack export keys
+const (
+	OutputInstances = "instances"
+	OutputPools     = "pools"
+)
+
+// Fleet is a component resource that parents every compute instance of a compute config
+type Fleet struct {
+	pulumi.ResourceState
+
+	// Instances holds the created instances keyed by their config name
+	Instances map[string]*core.Instance
+	// Volumes holds the data volumes of the instances keyed by instance then volume config name
+	Volumes map[string]map[string]*Volume
+	// Pools holds the created instance pools keyed by their config name
+	Pools map[string]*Pool
+}
+
+// NewFleet creates all instances and instance pools described by c as children of a single component.
+// Resource names are prefixed with the component name so that several fleets can be created in one stack.
+func NewFleet(ctx *pulumi.Context, name string, c *ComputeCfg, opts ...pulumi.ResourceOption) (*Fleet, error) {
+	component := &Fleet{}
+	if err := ctx.RegisterComponentResource(FleetComponentType, name, component, opts...); err != nil {
+		return nil, err
+	}
+
+	c.namePrefix = name
+	c.resourceOptions = []pulumi.ResourceOption{pulumi.Parent(component)}
+
+	// A fleet of pools only has no individual instances, a fleet without either is an error
+	var instances []*core.Instance
+	if len(c.Instances) > 0 || len(c.Pools) == 0 {
+		var err error
+		if instances, err = c.CreateAllInstances(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	component.Instances = make(map[string]*core.Instance, len(instances))
+	component.Volumes = make(map[string]map[string]*Volume)
+	for i, instance := range instances {
+		component.Instances[c.Instances[i].Name] = instance
+
+		volumes, err := c.CreateVolumes(ctx, i, instance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create volumes of instance %s: %w", c.Instances[i].Name, err)
+		}
+		if len(volumes) > 0 {
+			component.Volumes[c.Instances[i].Name] = volumes
+		}
+	}
+
+	component.Pools = make(map[string]*Pool, len(c.Pools))
+	for i, pool := range c.Pools {
+		created, err := c.CreatePool(ctx, i)
+		if err != nil {
+			return nil, err
+		}
+		component.Pools[pool.Name] = created
+	}
+
+	if err := ctx.RegisterResourceOutputs(component, component.Outputs()); err != nil {
+		return nil, err
+	}
+
+	return component, nil
+}
+
+// Outputs returns the instances keyed by config name with their id, private_ip, public_ip, the
+// image_id they were launched from, which records the image resolved by an image selector, and the
+// id and device of their data volumes keyed by volume name
+func (f *Fleet) Outputs() pulumi.Map {
+	instances := pulumi.Map{}
+	for name, instance := range f.Instances {
+		volumes := pulumi.Map{}
+		for volumeName, volume := range f.Volumes[name] {
+			volumes[volumeName] = pulumi.Map{
+				"id":     volume.Volume.ID().ToStringOutput(),
+				"device": volume.Attachment.Device,
+			}
+		}
+
+		instances[name] = pulumi.Map{
+			"id":         instance.ID().ToStringOutput(),
+			"private_ip": instance.PrivateIp,
+			"public_ip":  instance.PublicIp,
+			"image_id":   instance.SourceDetails.SourceId().Elem(),
+			"volumes":    volumes,
+		}
+	}
+
+	pools := pulumi.Map{}
+	for name, pool := range f.Pools {
+		pools[name] = pool.Outputs()
+	}
+
+	return pulumi.Map{
+		OutputInstances: instances,
+		OutputPools:     pools,
+	}
+}
@@ -0,0 +1,124 @@
+package compute
+
+import (
+	"infra/config"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// FleetMocks records the parent URN of every registered resource by name
+type FleetMocks struct {
+	mu      sync.Mutex
+	parents map[string]string
+}
+
+func (m *FleetMocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	m.mu.Lock()
+	if args.RegisterRPC != nil {
+		m.parents[args.Name] = args.RegisterRPC.GetParent()
+	} else {
+		m.parents[args.Name] = ""
+	}
+	m.mu.Unlock()
+	return args.Name + "_id", args.Inputs, nil
+}
+
+func (m *FleetMocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+	return args.Args, nil
+}
+
+func TestNewFleet(t *testing.T) {
+	mocks := &FleetMocks{parents: make(map[string]string)}
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		for _, name := range []string{"web", "batch"} {
+			computeCfg := newTestComputeCfg(testCompartmentID, []config.InstanceConfig{
+				newTestInstance("instance-1"),
+				newTestInstance("instance-2"),
+			})
+			fleet, err := NewFleet(ctx, name, &computeCfg)
+			if err != nil {
+				return err
+			}
+			if len(fleet.Instances) != 2 || fleet.Instances["instance-2"] == nil {
+				t.Errorf("Expected instances keyed by config name, but got %v", fleet.Instances)
+			}
+		}
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"web", "batch"} {
+		for _, instance := range []string{"instance-1", "instance-2"} {
+			childName := name + "-" + instance
+			parent, ok := mocks.parents[childName]
+			if !ok {
+				t.Errorf("Expected instance %s to be created", childName)
+				continue
+			}
+			if !strings.HasSuffix(parent, FleetComponentType+"::"+name) {
+				t.Errorf("Expected %s to be parented by the %s fleet, but got %q", childName, name, parent)
+			}
+		}
+	}
+}
+
+func TestNewFleetWithoutInstances(t *testing.T) {
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		computeCfg := newTestComputeCfg(testCompartmentID, nil)
+		_, err := NewFleet(ctx, "empty", &computeCfg)
+		return err
+	}, pulumi.WithMocks("project", "stack", ComputeMocks(0)))
+
+	if err == nil {
+		t.Errorf("Expected error but got none")
+	}
+}
+
+func TestFleetOutputs(t *testing.T) {
+	var wg sync.WaitGroup
+	var outputs map[string]interface{}
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		computeCfg := newTestComputeCfg(testCompartmentID, []config.InstanceConfig{newTestInstance("instance-1")})
+		fleet, err := NewFleet(ctx, "web", &computeCfg)
+		if err != nil {
+			return err
+		}
+
+		wg.Add(1)
+		fleet.Outputs().ToMapOutput().ApplyT(func(m map[string]interface{}) error {
+			defer wg.Done()
+			outputs = m
+			return nil
+		})
+		return nil
+	}, pulumi.WithMocks("project", "stack", &FleetMocks{parents: make(map[string]string)}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	wg.Wait()
+
+	instances, ok := outputs[OutputInstances].(map[string]interface{})
+	if !ok || len(instances) != 1 {
+		t.Fatalf("Expected 1 instance keyed by name, but got %v", outputs[OutputInstances])
+	}
+	instance := instances["instance-1"].(map[string]interface{})
+	if instance["id"] != "web-instance-1_id" {
+		t.Errorf("Expected instance id web-instance-1_id, but got %v", instance["id"])
+	}
+	for _, key := range []string{"private_ip", "public_ip"} {
+		if _, ok := instance[key]; !ok {
+			t.Errorf("Expected instance output %s, but got %v", key, instance)
+		}
+	}
+	if instance["image_id"] != testImageOCID {
+		t.Errorf("Expected image_id %s, but got %v", testImageOCID, instance["image_id"])
+	}
+}
@@ -0,0 +1,82 @@
+package compute
+
+import (
+	"fmt"
+	"infra/config"
+	"regexp"
+	"time"
+
+	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/core"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// ImageIDFor resolves the image of an instance, either the literal image_ocid or the image picked by
+// the image selector among the available images of the region, see SelectImage
+func (c *ComputeCfg) ImageIDFor(ctx *pulumi.Context, instance config.InstanceConfig, compartmentID pulumi.StringInput) (pulumi.StringInput, error) {
+	if instance.ImageOCID != "" {
+		return pulumi.String(instance.ImageOCID), nil
+	}
+	if instance.Image == nil {
+		return nil, fmt.Errorf("instance %s: image_ocid or image is required", instance.Name)
+	}
+
+	selector := *instance.Image
+	args := core.GetImagesOutputArgs{
+		CompartmentId:   compartmentID,
+		OperatingSystem: pulumi.String(selector.OperatingSystem),
+		State:           pulumi.String("AVAILABLE"),
+		SortBy:          pulumi.String("TIMECREATED"),
+		SortOrder:       pulumi.String("DESC"),
+	}
+	if selector.Version != "" {
+		args.OperatingSystemVersion = pulumi.String(selector.Version)
+	}
+	if !selector.AnyShape {
+		args.Shape = pulumi.String(instance.Shape)
+	}
+
+	images := core.GetImagesOutput(ctx, args)
+	return images.Images().ApplyT(func(images []core.GetImagesImage) (string, error) {
+		id, err := SelectImage(selector, images)
+		if err != nil {
+			return "", fmt.Errorf("instance %s: %w", instance.Name, err)
+		}
+		return id, nil
+	}).(pulumi.StringOutput), nil
+}
+
+// SelectImage returns the ID of the latest image matching the display name pattern of the selector,
+// or of the latest one created on its date when the selector is pinned to a date
+func SelectImage(selector config.ImageConfig, images []core.GetImagesImage) (string, error) {
+	var displayName *regexp.Regexp
+	if selector.DisplayName != "" {
+		re, err := regexp.Compile(selector.DisplayName)
+		if err != nil {
+			return "", fmt.Errorf("invalid image display name pattern: %w", err)
+		}
+		displayName = re
+	}
+
+	var selected string
+	var latest time.Time
+	for _, image := range images {
+		if displayName != nil && !displayName.MatchString(image.DisplayName) {
+			continue
+		}
+		created, err := time.Parse(time.RFC3339, image.TimeCreated)
+		if err != nil {
+			return "", fmt.Errorf("image %s: invalid creation time %q", image.DisplayName, image.TimeCreated)
+		}
+		if selector.Date != "" && created.UTC().Format(config.ImageDateLayout) != selector.Date {
+			continue
+		}
+		if selected == "" || created.After(latest) {
+			selected, latest = image.Id, created
+		}
+	}
+
+	if selected == "" {
+		return "", fmt.Errorf("no %s %s image matches display name %q and date %q", selector.OperatingSystem, selector.Version, selector.DisplayName, selector.Date)
+	}
+	return selected, nil
+}
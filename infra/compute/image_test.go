@@ -0,0 +1,105 @@
+package compute
+
+import (
+	"infra/config"
+	"testing"
+
+	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/core"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// testImages stands in for the images listed by core.GetImages, newest first
+var testImages = []core.GetImagesImage{
+	{Id: "ocid1.image.oc1..ol8-2024-03", DisplayName: "Oracle-Linux-8.9-2024.03.11-0", TimeCreated: "2024-03-12T10:00:00Z"},
+	{Id: "ocid1.image.oc1..ol8-aarch64-2024-02", DisplayName: "Oracle-Linux-8.9-aarch64-2024.02.29-0", TimeCreated: "2024-03-01T10:00:00Z"},
+	{Id: "ocid1.image.oc1..ol8-2024-01", DisplayName: "Oracle-Linux-8.9-2024.01.26-0", TimeCreated: "2024-01-26T10:00:00Z"},
+}
+
+// testImagesResult answers a getImages call with testImages
+func testImagesResult(args resource.PropertyMap) resource.PropertyMap {
+	var images []interface{}
+	for _, image := range testImages {
+		images = append(images, map[string]interface{}{
+			"id":          image.Id,
+			"displayName": image.DisplayName,
+			"timeCreated": image.TimeCreated,
+		})
+	}
+	result := args.Copy()
+	result["images"] = resource.NewPropertyValue(images)
+	return result
+}
+
+func TestSelectImage(t *testing.T) {
+	tests := []struct {
+		name          string
+		selector      config.ImageConfig
+		expected      string
+		expectedError bool
+	}{
+		{"Latest", config.ImageConfig{OperatingSystem: "Oracle Linux"}, "ocid1.image.oc1..ol8-2024-03", false},
+		{"Pinned date", config.ImageConfig{OperatingSystem: "Oracle Linux", Date: "2024-01-26"}, "ocid1.image.oc1..ol8-2024-01", false},
+		{"Display name", config.ImageConfig{OperatingSystem: "Oracle Linux", DisplayName: "aarch64"}, "ocid1.image.oc1..ol8-aarch64-2024-02", false},
+		{"Display name and date", config.ImageConfig{OperatingSystem: "Oracle Linux", DisplayName: `^Oracle-Linux-8\.9-\d{4}`, Date: "2024-03-01"}, "", true},
+		{"No match", config.ImageConfig{OperatingSystem: "Oracle Linux", DisplayName: "Oracle-Linux-9"}, "", true},
+		{"Invalid display name", config.ImageConfig{OperatingSystem: "Oracle Linux", DisplayName: "Oracle-Linux-(8"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := SelectImage(tt.selector, testImages)
+			if tt.expectedError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if id != tt.expected {
+				t.Errorf("Expected image %s, but got %s", tt.expected, id)
+			}
+		})
+	}
+}
+
+func TestCreateInstanceWithImageSelector(t *testing.T) {
+	tests := []struct {
+		name      string
+		imageOCID string
+		expected  string
+	}{
+		{"Selector", "", "ocid1.image.oc1..ol8-2024-03"},
+		{"Literal OCID wins", testImageOCID, testImageOCID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := newTestInstance("instance-1")
+			instance.ImageOCID = tt.imageOCID
+			instance.Image = &config.ImageConfig{OperatingSystem: "Oracle Linux", Version: "8"}
+			computeCfg := newTestComputeCfg(testCompartmentID, []config.InstanceConfig{instance})
+
+			err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+				created, err := computeCfg.CreateInstance(ctx, 0)
+				if err != nil {
+					return err
+				}
+
+				created.SourceDetails.SourceId().ApplyT(func(id *string) error {
+					if id == nil || *id != tt.expected {
+						t.Errorf("Expected image %s, but got %v", tt.expected, id)
+					}
+					return nil
+				})
+				return nil
+			}, pulumi.WithMocks("project", "stack", ComputeMocks(0)))
+
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
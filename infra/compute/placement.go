@@ -0,0 +1,89 @@
+package compute
+
+import (
+	"fmt"
+	"infra/config"
+	"strconv"
+	"strings"
+)
+
+// Placement is the availability domain and fault domain of an instance. An empty FaultDomain lets OCI
+// pick the fault domain.
+type Placement struct {
+	AvailabilityDomain string
+	FaultDomain        string
+}
+
+// PlaceInstance resolves the placement of the instance at index among the availability domains of the
+// region sorted by name. An availability_domain or fault_domain set on the instance wins over the spread
+// policy, which otherwise assigns availability domains then fault domains round-robin by index.
+func PlaceInstance(instance config.InstanceConfig, index int, spread string, availabilityDomains []string) (Placement, error) {
+	var placement Placement
+
+	switch {
+	case instance.AvailabilityDomain != "":
+		ad, err := resolveAvailabilityDomain(instance.AvailabilityDomain, availabilityDomains)
+		if err != nil {
+			return placement, err
+		}
+		placement.AvailabilityDomain = ad
+	case len(availabilityDomains) == 0:
+		return placement, fmt.Errorf("no availability domains found")
+	case spread == config.SpreadAvailabilityDomains:
+		placement.AvailabilityDomain = availabilityDomains[index%len(availabilityDomains)]
+	default:
+		placement.AvailabilityDomain = availabilityDomains[0]
+	}
+
+	switch {
+	case instance.FaultDomain != "":
+		fd, err := resolveFaultDomain(instance.FaultDomain)
+		if err != nil {
+			return placement, err
+		}
+		placement.FaultDomain = fd
+	case spread == config.SpreadFaultDomains:
+		placement.FaultDomain = faultDomainName(index % config.FaultDomainsPerAD)
+	case spread == config.SpreadAvailabilityDomains && len(availabilityDomains) > 0:
+		// Instances sharing an availability domain after a full round land in the next fault domain
+		placement.FaultDomain = faultDomainName(index / len(availabilityDomains) % config.FaultDomainsPerAD)
+	}
+
+	return placement, nil
+}
+
+// resolveAvailabilityDomain resolves a 1-based index or a name, with or without the tenancy prefix
+// such as Uocm:PHX-AD-1, to the full availability domain name
+func resolveAvailabilityDomain(ad string, availabilityDomains []string) (string, error) {
+	if n, err := strconv.Atoi(ad); err == nil {
+		if n < 1 || n > len(availabilityDomains) {
+			return "", fmt.Errorf("availability domain %d out of range, the region has %d", n, len(availabilityDomains))
+		}
+		return availabilityDomains[n-1], nil
+	}
+
+	// Names are used as is when the region's availability domains are unknown
+	if len(availabilityDomains) == 0 {
+		return ad, nil
+	}
+	for _, name := range availabilityDomains {
+		if strings.EqualFold(name, ad) || strings.HasSuffix(strings.ToUpper(name), ":"+strings.ToUpper(ad)) {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("availability domain %s not found in %v", ad, availabilityDomains)
+}
+
+// resolveFaultDomain resolves a 1-based index or a FAULT-DOMAIN-n name to the fault domain name
+func resolveFaultDomain(fd string) (string, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(fd), "FAULT-DOMAIN-"))
+	if err != nil || n < 1 || n > config.FaultDomainsPerAD {
+		return "", fmt.Errorf("invalid fault domain %s", fd)
+	}
+	return faultDomainName(n - 1), nil
+}
+
+// faultDomainName returns the name of the fault domain at a 0-based index
+func faultDomainName(i int) string {
+	return fmt.Sprintf("FAULT-DOMAIN-%d", i+1)
+}
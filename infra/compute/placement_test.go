@@ -0,0 +1,57 @@
+package compute
+
+import (
+	"infra/config"
+	"testing"
+)
+
+func TestPlaceInstance(t *testing.T) {
+	threeADs := []string{"Uocm:PHX-AD-1", "Uocm:PHX-AD-2", "Uocm:PHX-AD-3"}
+	oneAD := []string{"Uocm:US-SANJOSE-1-AD-1"}
+
+	tests := []struct {
+		name                string
+		instance            config.InstanceConfig
+		index               int
+		spread              string
+		availabilityDomains []string
+		expected            Placement
+		expectedError       bool
+	}{
+		{"No spread", config.InstanceConfig{}, 2, "", threeADs, Placement{"Uocm:PHX-AD-1", ""}, false},
+		{"Spread none", config.InstanceConfig{}, 2, config.SpreadNone, threeADs, Placement{"Uocm:PHX-AD-1", ""}, false},
+		{"Fault domains first", config.InstanceConfig{}, 0, config.SpreadFaultDomains, threeADs, Placement{"Uocm:PHX-AD-1", "FAULT-DOMAIN-1"}, false},
+		{"Fault domains wrap", config.InstanceConfig{}, 4, config.SpreadFaultDomains, threeADs, Placement{"Uocm:PHX-AD-1", "FAULT-DOMAIN-2"}, false},
+		{"Availability domains second", config.InstanceConfig{}, 1, config.SpreadAvailabilityDomains, threeADs, Placement{"Uocm:PHX-AD-2", "FAULT-DOMAIN-1"}, false},
+		{"Availability domains second round", config.InstanceConfig{}, 4, config.SpreadAvailabilityDomains, threeADs, Placement{"Uocm:PHX-AD-2", "FAULT-DOMAIN-2"}, false},
+		{"Availability domains single AD region", config.InstanceConfig{}, 1, config.SpreadAvailabilityDomains, oneAD, Placement{"Uocm:US-SANJOSE-1-AD-1", "FAULT-DOMAIN-2"}, false},
+		{"AD index", config.InstanceConfig{AvailabilityDomain: "3"}, 0, config.SpreadAvailabilityDomains, threeADs, Placement{"Uocm:PHX-AD-3", "FAULT-DOMAIN-1"}, false},
+		{"AD name without prefix", config.InstanceConfig{AvailabilityDomain: "phx-ad-2"}, 0, "", threeADs, Placement{"Uocm:PHX-AD-2", ""}, false},
+		{"AD full name", config.InstanceConfig{AvailabilityDomain: "Uocm:PHX-AD-3"}, 0, "", threeADs, Placement{"Uocm:PHX-AD-3", ""}, false},
+		{"AD name without lookup", config.InstanceConfig{AvailabilityDomain: "Uocm:PHX-AD-3"}, 0, "", nil, Placement{"Uocm:PHX-AD-3", ""}, false},
+		{"FD index", config.InstanceConfig{FaultDomain: "2"}, 0, config.SpreadFaultDomains, threeADs, Placement{"Uocm:PHX-AD-1", "FAULT-DOMAIN-2"}, false},
+		{"FD name", config.InstanceConfig{FaultDomain: "fault-domain-3"}, 0, "", threeADs, Placement{"Uocm:PHX-AD-1", "FAULT-DOMAIN-3"}, false},
+		{"AD index out of range", config.InstanceConfig{AvailabilityDomain: "2"}, 0, "", oneAD, Placement{}, true},
+		{"AD name not found", config.InstanceConfig{AvailabilityDomain: "IAD-AD-1"}, 0, "", threeADs, Placement{}, true},
+		{"Invalid FD", config.InstanceConfig{FaultDomain: "4"}, 0, "", threeADs, Placement{}, true},
+		{"No availability domains", config.InstanceConfig{}, 0, "", nil, Placement{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placement, err := PlaceInstance(tt.instance, tt.index, tt.spread, tt.availabilityDomains)
+			if tt.expectedError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if placement != tt.expected {
+				t.Errorf("Expected placement %v, but got %v", tt.expected, placement)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package compute
+
+import (
+	"fmt"
+	"infra/config"
+
+	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/core"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// Pool is an instance pool with the instance configuration its members are launched from
+type Pool struct {
+	Configuration *core.InstanceConfiguration
+	Pool          *core.InstancePool
+	// Members lists the instances of the pool once it is created
+	Members core.GetInstancePoolInstancesInstanceArrayOutput
+}
+
+// PoolPlacement is an availability domain of an instance pool with the fault domains its members are
+// spread across. No fault domains lets OCI pick them.
+type PoolPlacement struct {
+	AvailabilityDomain string
+	FaultDomains       []string
+}
+
+// PlacePool resolves the placement of a pool among the availability domains of the region sorted by name.
+// The availability_domains and fault_domains of the pool win over the spread policy, which otherwise places
+// the pool in every availability domain, or the first one, and across all of their fault domains.
+func PlacePool(pool config.InstancePoolConfig, spread string, availabilityDomains []string) ([]PoolPlacement, error) {
+	var ads []string
+	switch {
+	case len(pool.AvailabilityDomains) > 0:
+		for _, ad := range pool.AvailabilityDomains {
+			name, err := resolveAvailabilityDomain(ad, availabilityDomains)
+			if err != nil {
+				return nil, err
+			}
+			ads = append(ads, name)
+		}
+	case len(availabilityDomains) == 0:
+		return nil, fmt.Errorf("no availability domains found")
+	case spread == config.SpreadAvailabilityDomains:
+		ads = availabilityDomains
+	default:
+		ads = availabilityDomains[:1]
+	}
+
+	var fds []string
+	switch {
+	case len(pool.FaultDomains) > 0:
+		for _, fd := range pool.FaultDomains {
+			name, err := resolveFaultDomain(fd)
+			if err != nil {
+				return nil, err
+			}
+			fds = append(fds, name)
+		}
+	case spread == config.SpreadFaultDomains, spread == config.SpreadAvailabilityDomains:
+		for i := 0; i < config.FaultDomainsPerAD; i++ {
+			fds = append(fds, faultDomainName(i))
+		}
+	}
+
+	placements := make([]PoolPlacement, 0, len(ads))
+	for _, ad := range ads {
+		placements = append(placements, PoolPlacement{AvailabilityDomain: ad, FaultDomains: fds})
+	}
+	return placements, nil
+}
+
+// CreatePool creates the instance configuration of the pool at poolIndex from its template, with the
+// same subnet, image, shape and metadata resolution as CreateInstance, and the pool launching its members
+func (c *ComputeCfg) CreatePool(ctx *pulumi.Context, poolIndex int) (*Pool, error) {
+	if poolIndex < 0 || poolIndex >= len(c.Pools) {
+		return nil, fmt.Errorf("pool index %d out of range", poolIndex)
+	}
+
+	pool := c.Pools[poolIndex]
+	template := pool.Template
+	if template.Name == "" {
+		template.Name = pool.Name
+	}
+
+	subnetID, err := c.SubnetIDFor(template)
+	if err != nil {
+		return nil, err
+	}
+
+	nsgIDs, err := c.NSGIDsFor(template)
+	if err != nil {
+		return nil, err
+	}
+
+	compartmentID, err := c.CompartmentIDInput(c.Compartments)
+	if err != nil {
+		return nil, err
+	}
+
+	imageID, err := c.ImageIDFor(ctx, template, compartmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	vnicDetails := &core.InstanceConfigurationInstanceDetailsLaunchDetailsCreateVnicDetailsArgs{
+		SubnetId: subnetID.ToStringOutput().ToStringPtrOutput(),
+		NsgIds:   nsgIDs,
+	}
+	if template.AssignPublicIP != nil {
+		vnicDetails.AssignPublicIp = pulumi.Bool(*template.AssignPublicIP)
+	}
+
+	sourceDetails := &core.InstanceConfigurationInstanceDetailsLaunchDetailsSourceDetailsArgs{
+		SourceType: pulumi.String("image"),
+		ImageId:    imageID.ToStringOutput().ToStringPtrOutput(),
+	}
+	sourceDetails.BootVolumeSizeInGbs, sourceDetails.BootVolumeVpusPerGb = bootVolume(template)
+
+	launchDetails := &core.InstanceConfigurationInstanceDetailsLaunchDetailsArgs{
+		CompartmentId:     compartmentID.ToStringOutput().ToStringPtrOutput(),
+		Shape:             pulumi.String(template.Shape),
+		SourceDetails:     sourceDetails,
+		CreateVnicDetails: vnicDetails,
+		Metadata:          c.MetadataFor(template),
+	}
+	if ocpus, memoryGB := shapeResources(template); ocpus != nil || memoryGB != nil {
+		launchDetails.ShapeConfig = &core.InstanceConfigurationInstanceDetailsLaunchDetailsShapeConfigArgs{
+			Ocpus:       ocpus,
+			MemoryInGbs: memoryGB,
+		}
+	}
+	if template.BastionPlugin {
+		launchDetails.AgentConfig = &core.InstanceConfigurationInstanceDetailsLaunchDetailsAgentConfigArgs{
+			PluginsConfigs: core.InstanceConfigurationInstanceDetailsLaunchDetailsAgentConfigPluginsConfigArray{
+				core.InstanceConfigurationInstanceDetailsLaunchDetailsAgentConfigPluginsConfigArgs{
+					Name:         pulumi.String(bastionPlugin),
+					DesiredState: pulumi.String("ENABLED"),
+				},
+			},
+		}
+	}
+
+	configuration, err := core.NewInstanceConfiguration(ctx, c.resourceName(pool.Name+"-configuration"), &core.InstanceConfigurationArgs{
+		CompartmentId: compartmentID,
+		DisplayName:   pulumi.String(pool.Name),
+		InstanceDetails: &core.InstanceConfigurationInstanceDetailsArgs{
+			InstanceType:  pulumi.String("compute"),
+			LaunchDetails: launchDetails,
+		},
+	}, c.resourceOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create instance configuration for pool %s: %w", pool.Name, err)
+	}
+
+	availabilityDomains := c.AvailabilityDomains
+	if availabilityDomains == nil {
+		availabilityDomains = pulumi.StringArray{}
+	}
+	placements := pulumi.All(availabilityDomains, subnetID).ApplyT(func(args []interface{}) ([]core.InstancePoolPlacementConfiguration, error) {
+		placements, err := PlacePool(pool, c.Spread, args[0].([]string))
+		if err != nil {
+			return nil, fmt.Errorf("pool %s: %w", pool.Name, err)
+		}
+		configs := make([]core.InstancePoolPlacementConfiguration, 0, len(placements))
+		for _, p := range placements {
+			configs = append(configs, core.InstancePoolPlacementConfiguration{
+				AvailabilityDomain: p.AvailabilityDomain,
+				FaultDomains:       p.FaultDomains,
+				PrimaryVnicSubnets: &core.InstancePoolPlacementConfigurationPrimaryVnicSubnets{
+					SubnetId: args[1].(string),
+				},
+			})
+		}
+		return configs, nil
+	}).(core.InstancePoolPlacementConfigurationArrayOutput)
+
+	instancePool, err := core.NewInstancePool(ctx, c.resourceName(pool.Name), &core.InstancePoolArgs{
+		CompartmentId:                compartmentID,
+		DisplayName:                  pulumi.String(pool.Name),
+		InstanceConfigurationId:      configuration.ID().ToStringOutput(),
+		InstanceDisplayNameFormatter: pulumi.String(pool.Name + "-${launchCount}"),
+		PlacementConfigurations:      placements,
+		Size:                         pulumi.Int(pool.Size),
+	}, c.resourceOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pool %s: %w", pool.Name, err)
+	}
+
+	members := core.GetInstancePoolInstancesOutput(ctx, core.GetInstancePoolInstancesOutputArgs{
+		CompartmentId:  compartmentID,
+		InstancePoolId: instancePool.ID().ToStringOutput(),
+	}).Instances()
+
+	return &Pool{Configuration: configuration, Pool: instancePool, Members: members}, nil
+}
+
+// Outputs returns the pool id, its instance_configuration_id, size and members with their id, display_name,
+// availability_domain and fault_domain
+func (p *Pool) Outputs() pulumi.Map {
+	members := p.Members.ApplyT(func(instances []core.GetInstancePoolInstancesInstance) []map[string]interface{} {
+		members := make([]map[string]interface{}, 0, len(instances))
+		for _, instance := range instances {
+			members = append(members, map[string]interface{}{
+				"id":                  instance.InstanceId,
+				"display_name":        instance.DisplayName,
+				"availability_domain": instance.AvailabilityDomain,
+				"fault_domain":        instance.FaultDomain,
+			})
+		}
+		return members
+	}).(pulumi.MapArrayOutput)
+
+	return pulumi.Map{
+		"id":                        p.Pool.ID().ToStringOutput(),
+		"instance_configuration_id": p.Configuration.ID().ToStringOutput(),
+		"size":                      p.Pool.Size,
+		"members":                   members,
+	}
+}
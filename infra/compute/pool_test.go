@@ -0,0 +1,177 @@
+package compute
+
+import (
+	"infra/config"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// PoolMocks records the inputs of every created resource by name and lists two members for every pool
+type PoolMocks struct {
+	mu        sync.Mutex
+	resources map[string]resource.PropertyMap
+}
+
+func (m *PoolMocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	m.mu.Lock()
+	m.resources[args.Name] = args.Inputs
+	m.mu.Unlock()
+	return args.Name + "_id", args.Inputs, nil
+}
+
+func (m *PoolMocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+	if args.Token != "oci:Core/getInstancePoolInstances:getInstancePoolInstances" {
+		return ComputeMocks(0).Call(args)
+	}
+	poolID := args.Args["instancePoolId"].StringValue()
+	var members []interface{}
+	for i, fd := range []string{"FAULT-DOMAIN-1", "FAULT-DOMAIN-2"} {
+		members = append(members, map[string]interface{}{
+			"instanceId":         poolID + "-member-" + strconv.Itoa(i+1),
+			"displayName":        "web-" + strconv.Itoa(i+1),
+			"availabilityDomain": "Uocm:PHX-AD-1",
+			"faultDomain":        fd,
+		})
+	}
+	return resource.NewPropertyMapFromMap(map[string]interface{}{"instances": members}), nil
+}
+
+func newPoolMocks() *PoolMocks {
+	return &PoolMocks{resources: make(map[string]resource.PropertyMap)}
+}
+
+func TestPlacePool(t *testing.T) {
+	threeADs := []string{"Uocm:PHX-AD-1", "Uocm:PHX-AD-2", "Uocm:PHX-AD-3"}
+	allFDs := []string{"FAULT-DOMAIN-1", "FAULT-DOMAIN-2", "FAULT-DOMAIN-3"}
+
+	tests := []struct {
+		name          string
+		pool          config.InstancePoolConfig
+		spread        string
+		expected      []PoolPlacement
+		expectedError bool
+	}{
+		{"No spread", config.InstancePoolConfig{}, "", []PoolPlacement{{"Uocm:PHX-AD-1", nil}}, false},
+		{"Fault domains", config.InstancePoolConfig{}, config.SpreadFaultDomains, []PoolPlacement{{"Uocm:PHX-AD-1", allFDs}}, false},
+		{"Availability domains", config.InstancePoolConfig{}, config.SpreadAvailabilityDomains, []PoolPlacement{
+			{"Uocm:PHX-AD-1", allFDs}, {"Uocm:PHX-AD-2", allFDs}, {"Uocm:PHX-AD-3", allFDs},
+		}, false},
+		{"Explicit placement", config.InstancePoolConfig{AvailabilityDomains: []string{"3", "PHX-AD-1"}, FaultDomains: []string{"2"}}, config.SpreadAvailabilityDomains, []PoolPlacement{
+			{"Uocm:PHX-AD-3", []string{"FAULT-DOMAIN-2"}}, {"Uocm:PHX-AD-1", []string{"FAULT-DOMAIN-2"}},
+		}, false},
+		{"Unknown availability domain", config.InstancePoolConfig{AvailabilityDomains: []string{"4"}}, "", nil, true},
+		{"Invalid fault domain", config.InstancePoolConfig{FaultDomains: []string{"FAULT-DOMAIN-9"}}, "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placements, err := PlacePool(tt.pool, tt.spread, threeADs)
+			if tt.expectedError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(placements, tt.expected) {
+				t.Errorf("Expected placements %v, but got %v", tt.expected, placements)
+			}
+		})
+	}
+}
+
+func TestNewFleetWithPool(t *testing.T) {
+	ocpus := 2.0
+	template := newTestInstance("")
+	template.OCPUCount = &ocpus
+	template.BastionPlugin = true
+	template.BootVolumeSizeGB = 100
+	computeCfg := newTestComputeCfg(testCompartmentID, nil)
+	computeCfg.Spread = config.SpreadFaultDomains
+	computeCfg.Pools = []config.InstancePoolConfig{{Name: "web", Size: 2, Template: template}}
+	mocks := newPoolMocks()
+	var wg sync.WaitGroup
+	var outputs map[string]interface{}
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		fleet, err := NewFleet(ctx, "app", &computeCfg)
+		if err != nil {
+			return err
+		}
+		if len(fleet.Instances) != 0 || fleet.Pools["web"] == nil {
+			t.Errorf("Expected only the web pool, but got instances %v and pools %v", fleet.Instances, fleet.Pools)
+		}
+
+		wg.Add(1)
+		fleet.Outputs().ToMapOutput().ApplyT(func(m map[string]interface{}) error {
+			defer wg.Done()
+			outputs = m
+			return nil
+		})
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	wg.Wait()
+
+	launch := mocks.resources["app-web-configuration"]["instanceDetails"].ObjectValue()["launchDetails"].ObjectValue()
+	expected := map[string]interface{}{
+		"shape":         "VM.Standard.E4.Flex",
+		"compartmentId": testCompartmentID,
+		"metadata":      map[string]interface{}{"ssh_authorized_keys": testSSHPublicKey},
+		"sourceDetails": map[string]interface{}{"sourceType": "image", "imageId": testImageOCID, "bootVolumeSizeInGbs": "100"},
+		"shapeConfig":   map[string]interface{}{"ocpus": ocpus},
+	}
+	for key, value := range expected {
+		if got := launch[resource.PropertyKey(key)].Mappable(); !reflect.DeepEqual(got, value) {
+			t.Errorf("Expected launch details %s %v, but got %v", key, value, got)
+		}
+	}
+	if got := launch["createVnicDetails"].ObjectValue()["subnetId"].StringValue(); got != testSubnetID {
+		t.Errorf("Expected subnet %s, but got %s", testSubnetID, got)
+	}
+	if plugins := launch["agentConfig"].ObjectValue()["pluginsConfigs"].ArrayValue(); len(plugins) != 1 || plugins[0].ObjectValue()["name"].StringValue() != "Bastion" {
+		t.Errorf("Expected the Bastion plugin to be enabled, but got %v", plugins)
+	}
+
+	pool := mocks.resources["app-web"]
+	if got := pool["size"].NumberValue(); got != 2 {
+		t.Errorf("Expected pool size 2, but got %v", got)
+	}
+	if got := pool["instanceConfigurationId"].StringValue(); got != "app-web-configuration_id" {
+		t.Errorf("Expected instance configuration app-web-configuration_id, but got %s", got)
+	}
+	placements := pool["placementConfigurations"].Mappable()
+	expectedPlacements := []interface{}{map[string]interface{}{
+		"availabilityDomain": "Uocm:PHX-AD-1",
+		"faultDomains":       []interface{}{"FAULT-DOMAIN-1", "FAULT-DOMAIN-2", "FAULT-DOMAIN-3"},
+		"primaryVnicSubnets": map[string]interface{}{"subnetId": testSubnetID},
+	}}
+	if !reflect.DeepEqual(placements, expectedPlacements) {
+		t.Errorf("Expected placements %v, but got %v", expectedPlacements, placements)
+	}
+
+	pools, ok := outputs[OutputPools].(map[string]interface{})
+	if !ok || pools["web"] == nil {
+		t.Fatalf("Expected the web pool keyed by name, but got %v", outputs[OutputPools])
+	}
+	web := pools["web"].(map[string]interface{})
+	if web["id"] != "app-web_id" {
+		t.Errorf("Expected pool id app-web_id, but got %v", web["id"])
+	}
+	members, ok := web["members"].([]map[string]interface{})
+	if !ok || len(members) != 2 {
+		t.Fatalf("Expected 2 pool members, but got %v", web["members"])
+	}
+	if member := members[1]; member["id"] != "app-web_id-member-2" || member["fault_domain"] != "FAULT-DOMAIN-2" {
+		t.Errorf("Expected member app-web_id-member-2 in FAULT-DOMAIN-2, but got %v", member)
+	}
+}
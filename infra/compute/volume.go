@@ -0,0 +1,113 @@
+package compute
+
+import (
+	"fmt"
+	"infra/config"
+	"strconv"
+	"strings"
+
+	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/core"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// Volume is a data volume with its attachment to an instance and its optional backup policy assignment
+type Volume struct {
+	Volume       *core.Volume
+	Attachment   *core.VolumeAttachment
+	BackupPolicy *core.VolumeBackupPolicyAssignment
+}
+
+// CreateVolumes creates the data volumes of the instance at instanceIndex in its availability domain and
+// attaches them to instance. The volumes do not depend on the instance itself, so that they are kept and
+// attached to the new instance when it is replaced.
+func (c *ComputeCfg) CreateVolumes(ctx *pulumi.Context, instanceIndex int, instance *core.Instance) (map[string]*Volume, error) {
+	if instanceIndex < 0 || instanceIndex >= len(c.Instances) {
+		return nil, fmt.Errorf("instance index %d out of range", instanceIndex)
+	}
+
+	instanceCfg := c.Instances[instanceIndex]
+	if len(instanceCfg.Volumes) == 0 {
+		return nil, nil
+	}
+
+	compartmentID, err := c.CompartmentIDInput(c.Compartments)
+	if err != nil {
+		return nil, err
+	}
+
+	availabilityDomain, _, err := c.PlacementFor(instanceCfg, instanceIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	volumes := make(map[string]*Volume, len(instanceCfg.Volumes))
+	for _, v := range instanceCfg.Volumes {
+		displayName := instanceCfg.Name + "-" + v.Name
+		name := c.resourceName(displayName)
+
+		args := &core.VolumeArgs{
+			CompartmentId:      compartmentID,
+			AvailabilityDomain: availabilityDomain,
+			DisplayName:        pulumi.String(displayName),
+			SizeInGbs:          pulumi.String(strconv.Itoa(v.SizeGB)),
+		}
+		if v.VPUs != nil {
+			args.VpusPerGb = pulumi.String(strconv.Itoa(*v.VPUs))
+		}
+		volume, err := core.NewVolume(ctx, name, args, c.resourceOptions...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create volume %s: %w", displayName, err)
+		}
+
+		attachmentType := v.Attachment
+		if attachmentType == "" {
+			attachmentType = config.AttachmentParavirtualized
+		}
+		attachmentArgs := &core.VolumeAttachmentArgs{
+			AttachmentType: pulumi.String(attachmentType),
+			InstanceId:     instance.ID().ToStringOutput(),
+			VolumeId:       volume.ID().ToStringOutput(),
+			DisplayName:    pulumi.String(displayName),
+		}
+		if v.Device != "" {
+			attachmentArgs.Device = pulumi.String(v.Device)
+		}
+		attachment, err := core.NewVolumeAttachment(ctx, name+"-attachment", attachmentArgs, c.resourceOptions...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to attach volume %s: %w", displayName, err)
+		}
+
+		created := &Volume{Volume: volume, Attachment: attachment}
+		if v.BackupPolicy != "" {
+			created.BackupPolicy, err = core.NewVolumeBackupPolicyAssignment(ctx, name+"-backup-policy", &core.VolumeBackupPolicyAssignmentArgs{
+				AssetId:  volume.ID().ToStringOutput(),
+				PolicyId: backupPolicyID(ctx, v.BackupPolicy),
+			}, c.resourceOptions...)
+			if err != nil {
+				return nil, fmt.Errorf("failed to assign backup policy to volume %s: %w", displayName, err)
+			}
+		}
+		volumes[v.Name] = created
+	}
+
+	return volumes, nil
+}
+
+// backupPolicyID resolves an Oracle defined backup policy such as silver to its OCID, other values
+// are policy OCIDs
+func backupPolicyID(ctx *pulumi.Context, policy string) pulumi.StringInput {
+	if strings.HasPrefix(policy, "ocid1.") {
+		return pulumi.String(policy)
+	}
+
+	// Oracle defined policies are listed when no compartment is given
+	policies := core.GetVolumeBackupPoliciesOutput(ctx, core.GetVolumeBackupPoliciesOutputArgs{})
+	return policies.VolumeBackupPolicies().ApplyT(func(policies []core.GetVolumeBackupPoliciesVolumeBackupPolicy) (string, error) {
+		for _, p := range policies {
+			if p.DisplayName == policy {
+				return p.Id, nil
+			}
+		}
+		return "", fmt.Errorf("volume backup policy %s not found", policy)
+	}).(pulumi.StringOutput)
+}
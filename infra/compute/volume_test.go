@@ -0,0 +1,134 @@
+package compute
+
+import (
+	"infra/config"
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// VolumeMocks records the inputs of every created resource by name and lists the Oracle defined
+// volume backup policies
+type VolumeMocks struct {
+	mu        sync.Mutex
+	resources map[string]resource.PropertyMap
+}
+
+func (m *VolumeMocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	m.mu.Lock()
+	m.resources[args.Name] = args.Inputs
+	m.mu.Unlock()
+	return args.Name + "_id", args.Inputs, nil
+}
+
+func (m *VolumeMocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+	if args.Token != "oci:Core/getVolumeBackupPolicies:getVolumeBackupPolicies" {
+		return ComputeMocks(0).Call(args)
+	}
+	var policies []interface{}
+	for _, name := range []string{config.BackupPolicyGold, config.BackupPolicySilver, config.BackupPolicyBronze} {
+		policies = append(policies, map[string]interface{}{"displayName": name, "id": "ocid1.volumebackuppolicy.oc1.." + name})
+	}
+	return resource.NewPropertyMapFromMap(map[string]interface{}{"volumeBackupPolicies": policies}), nil
+}
+
+func newVolumeMocks() *VolumeMocks {
+	return &VolumeMocks{resources: make(map[string]resource.PropertyMap)}
+}
+
+func TestCreateVolumes(t *testing.T) {
+	vpus, bootVPUs := 20, 30
+	instance := newTestInstance("db")
+	instance.AvailabilityDomain = "2"
+	instance.BootVolumeSizeGB, instance.BootVolumeVPUs = 100, &bootVPUs
+	instance.Volumes = []config.BlockVolumeConfig{
+		{Name: "data", SizeGB: 500, VPUs: &vpus, Attachment: config.AttachmentISCSI, Device: "/dev/oracleoci/oraclevdb", BackupPolicy: config.BackupPolicySilver},
+		{Name: "scratch", SizeGB: 50},
+	}
+	computeCfg := newTestComputeCfg(testCompartmentID, []config.InstanceConfig{instance})
+	mocks := newVolumeMocks()
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		fleet, err := NewFleet(ctx, "web", &computeCfg)
+		if err != nil {
+			return err
+		}
+		if volumes := fleet.Volumes["db"]; len(volumes) != 2 || volumes["data"].BackupPolicy == nil || volumes["scratch"].BackupPolicy != nil {
+			t.Errorf("Expected volumes data with a backup policy and scratch without, but got %v", volumes)
+		}
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	source := mocks.resources["web-db"]["sourceDetails"].ObjectValue()
+	if got := source["bootVolumeSizeInGbs"].StringValue(); got != "100" {
+		t.Errorf("Expected boot volume size 100, but got %s", got)
+	}
+	if got := source["bootVolumeVpusPerGb"].StringValue(); got != "30" {
+		t.Errorf("Expected boot volume VPUs 30, but got %s", got)
+	}
+
+	tests := []struct {
+		resource string
+		expected map[string]string
+	}{
+		{"web-db-data", map[string]string{
+			"availabilityDomain": "Uocm:PHX-AD-2",
+			"compartmentId":      testCompartmentID,
+			"sizeInGbs":          "500",
+			"vpusPerGb":          "20",
+		}},
+		{"web-db-data-attachment", map[string]string{
+			"attachmentType": config.AttachmentISCSI,
+			"instanceId":     "web-db_id",
+			"volumeId":       "web-db-data_id",
+			"device":         "/dev/oracleoci/oraclevdb",
+		}},
+		{"web-db-data-backup-policy", map[string]string{
+			"assetId":  "web-db-data_id",
+			"policyId": "ocid1.volumebackuppolicy.oc1..silver",
+		}},
+		{"web-db-scratch", map[string]string{
+			"availabilityDomain": "Uocm:PHX-AD-2",
+			"sizeInGbs":          "50",
+		}},
+		{"web-db-scratch-attachment", map[string]string{
+			"attachmentType": config.AttachmentParavirtualized,
+			"volumeId":       "web-db-scratch_id",
+		}},
+	}
+	for _, tt := range tests {
+		inputs, ok := mocks.resources[tt.resource]
+		if !ok {
+			t.Errorf("Expected %s to be created", tt.resource)
+			continue
+		}
+		for key, value := range tt.expected {
+			if got := inputs[resource.PropertyKey(key)]; !got.IsString() || got.StringValue() != value {
+				t.Errorf("Expected %s %s to be %s, but got %v", tt.resource, key, value, got)
+			}
+		}
+	}
+	if _, ok := mocks.resources["web-db-scratch-backup-policy"]; ok {
+		t.Errorf("Expected no backup policy for web-db-scratch")
+	}
+}
+
+func TestCreateVolumesUnknownBackupPolicy(t *testing.T) {
+	instance := newTestInstance("db")
+	instance.Volumes = []config.BlockVolumeConfig{{Name: "data", SizeGB: 50, BackupPolicy: "platinum"}}
+	computeCfg := newTestComputeCfg(testCompartmentID, []config.InstanceConfig{instance})
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		_, err := NewFleet(ctx, "web", &computeCfg)
+		return err
+	}, pulumi.WithMocks("project", "stack", newVolumeMocks()))
+
+	if err == nil {
+		t.Errorf("Expected error but got none")
+	}
+}
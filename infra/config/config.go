@@ -1,80 +1,374 @@
 package config
 
-import (
-	"os"
-
-	"gopkg.in/yaml.v3"
-)
-
+// BaseConfig holds the settings shared by every section. The compartment is either a literal
+// compartment_id or the name of a compartment of the identity section.
 type BaseConfig struct {
-	CompartmentID string `yaml:"compartment_id"`
+	CompartmentID string `yaml:"compartment_id,omitempty"`
+	Compartment   string `yaml:"compartment,omitempty"`
 	Region        string `yaml:"region,omitempty"`
 }
 
+// SubnetConfig describes a subnet of the VCN. Subnets are private unless public is set, which
+// prohibits public IPs on their VNICs. Subnets are regional unless an availability_domain is given.
 type SubnetConfig struct {
-	Name      string `yaml:"name"`
-	CidrBlock string `yaml:"cidr_block"`
+	Name               string `yaml:"name"`
+	CidrBlock          string `yaml:"cidr_block"`
+	Public             bool   `yaml:"public,omitempty"`
+	DNSLabel           string `yaml:"dns_label,omitempty"`
+	AvailabilityDomain string `yaml:"availability_domain,omitempty"`
+	RouteTable         string `yaml:"route_table,omitempty"`
+	DHCPOptions        string `yaml:"dhcp_options,omitempty"`
+}
+
+// DHCPOptionsConfig describes a named set of DHCP options that subnets reference with dhcp_options.
+// ServerType is VcnLocalPlusInternet (default) or CustomDnsServer, which requires custom_dns_servers.
+type DHCPOptionsConfig struct {
+	Name             string   `yaml:"name"`
+	ServerType       string   `yaml:"server_type,omitempty"`
+	CustomDNSServers []string `yaml:"custom_dns_servers,omitempty"`
+	SearchDomains    []string `yaml:"search_domains,omitempty"`
+}
+
+// GatewayConfig describes an internet, nat or service gateway attached to the VCN.
+// Services selects the OCI services of a service gateway: "all" (default) or "objectstorage".
+type GatewayConfig struct {
+	Name     string `yaml:"name"`
+	Type     string `yaml:"type"`
+	Services string `yaml:"services,omitempty"`
+}
+
+// RouteRuleConfig routes a destination CIDR block through a gateway referenced by name.
+// Rules targeting a service gateway route to its services and ignore destination.
+type RouteRuleConfig struct {
+	Destination string `yaml:"destination,omitempty"`
+	Gateway     string `yaml:"gateway"`
+	Description string `yaml:"description"`
+}
+
+type RouteTableConfig struct {
+	Name  string            `yaml:"name"`
+	Rules []RouteRuleConfig `yaml:"rules"`
 }
 
+// TCPOptionConfig restricts a rule to a destination port range and optionally a source port range
 type TCPOptionConfig struct {
-	MinPort int `yaml:"min_port"`
-	MaxPort int `yaml:"max_port"`
+	MinPort       int `yaml:"min_port"`
+	MaxPort       int `yaml:"max_port"`
+	SourceMinPort int `yaml:"source_min_port,omitempty"`
+	SourceMaxPort int `yaml:"source_max_port,omitempty"`
+}
+
+// UDPOptionConfig shares the port ranges of TCPOptionConfig
+type UDPOptionConfig = TCPOptionConfig
+
+// ICMPOptionConfig restricts an ICMP rule to a message type and optionally a code
+type ICMPOptionConfig struct {
+	Type int  `yaml:"type"`
+	Code *int `yaml:"code,omitempty"`
+}
+
+// SecurityRuleConfig is a single ingress or egress rule of a security list. Ingress rules match
+// source and sources, egress rules match destination and destinations.
+type SecurityRuleConfig struct {
+	Protocol     string            `yaml:"protocol"`
+	Description  string            `yaml:"description,omitempty"`
+	Destination  string            `yaml:"destination,omitempty"`
+	Destinations []string          `yaml:"destinations,omitempty"`
+	Source       string            `yaml:"source,omitempty"`
+	Sources      []string          `yaml:"sources,omitempty"`
+	Stateless    bool              `yaml:"stateless,omitempty"`
+	TCPOptions   []TCPOptionConfig `yaml:"tcp_options,omitempty"`
+	UDPOptions   []UDPOptionConfig `yaml:"udp_options,omitempty"`
+	ICMPOptions  *ICMPOptionConfig `yaml:"icmp_options,omitempty"`
 }
 
+// SourceCIDRs returns source followed by sources, skipping unset values
+func (r SecurityRuleConfig) SourceCIDRs() []string {
+	return endpoints(r.Source, r.Sources)
+}
+
+// DestinationCIDRs returns destination followed by destinations, skipping unset values
+func (r SecurityRuleConfig) DestinationCIDRs() []string {
+	return endpoints(r.Destination, r.Destinations)
+}
+
+// SecurityListConfig describes a security list holding many ingress_rules and egress_rules.
+// The flat format, where protocol, source and destination describe a single rule directly on the
+// list, is still accepted and migrated by NetworkConfig.GroupedSecurityLists.
 type SecurityListConfig struct {
-	DisplayName string            `yaml:"display_name"`
+	DisplayName  string               `yaml:"display_name"`
+	SubnetName   string               `yaml:"subnet_name,omitempty"`
+	IngressRules []SecurityRuleConfig `yaml:"ingress_rules,omitempty"`
+	EgressRules  []SecurityRuleConfig `yaml:"egress_rules,omitempty"`
+
+	Protocol     string            `yaml:"protocol,omitempty"`
+	Description  string            `yaml:"description,omitempty"`
+	Destination  string            `yaml:"destination,omitempty"`
+	Destinations []string          `yaml:"destinations,omitempty"`
+	Source       string            `yaml:"source,omitempty"`
+	Sources      []string          `yaml:"sources,omitempty"`
+	Stateless    bool              `yaml:"stateless,omitempty"`
+	TCPOptions   []TCPOptionConfig `yaml:"tcp_options,omitempty"`
+	UDPOptions   []UDPOptionConfig `yaml:"udp_options,omitempty"`
+	ICMPOptions  *ICMPOptionConfig `yaml:"icmp_options,omitempty"`
+}
+
+// SourceCIDRs returns source followed by sources of a flat security list, skipping unset values
+func (s SecurityListConfig) SourceCIDRs() []string {
+	return endpoints(s.Source, s.Sources)
+}
+
+// DestinationCIDRs returns destination followed by destinations of a flat security list, skipping unset values
+func (s SecurityListConfig) DestinationCIDRs() []string {
+	return endpoints(s.Destination, s.Destinations)
+}
+
+func endpoints(single string, list []string) []string {
+	var result []string
+	for _, value := range append([]string{single}, list...) {
+		if value != "" && value != "null" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// NSGRuleConfig is a single rule of a network security group. Source and destination accept either
+// a CIDR block or the display_name of another network security group.
+type NSGRuleConfig struct {
+	Direction   string            `yaml:"direction"`
 	Protocol    string            `yaml:"protocol"`
 	Description string            `yaml:"description"`
-	Destination string            `yaml:"destination"`
-	Source      string            `yaml:"source"`
+	Source      string            `yaml:"source,omitempty"`
+	Destination string            `yaml:"destination,omitempty"`
 	Stateless   bool              `yaml:"stateless"`
 	TCPOptions  []TCPOptionConfig `yaml:"tcp_options"`
-	SubnetName  string            `yaml:"subnet_name,omitempty"`
+	UDPOptions  []UDPOptionConfig `yaml:"udp_options,omitempty"`
+	ICMPOptions *ICMPOptionConfig `yaml:"icmp_options,omitempty"`
+}
+
+type NetworkSecurityGroupConfig struct {
+	DisplayName string          `yaml:"display_name"`
+	Rules       []NSGRuleConfig `yaml:"rules"`
 }
 
 type NetworkConfig struct {
-	BaseConfig
-	CidrBlock     string               `yaml:"cidr_block"`
-	DisplayName   string               `yaml:"display_name"`
-	Subnets       []SubnetConfig       `yaml:"subnets"`
-	SecurityLists []SecurityListConfig `yaml:"security_lists"`
+	BaseConfig            `yaml:",inline"`
+	CidrBlock             string                       `yaml:"cidr_block"`
+	DisplayName           string                       `yaml:"display_name"`
+	DNSLabel              string                       `yaml:"dns_label,omitempty"`
+	Subnets               []SubnetConfig               `yaml:"subnets"`
+	SecurityLists         []SecurityListConfig         `yaml:"security_lists"`
+	NetworkSecurityGroups []NetworkSecurityGroupConfig `yaml:"network_security_groups,omitempty"`
+	Gateways              []GatewayConfig              `yaml:"gateways,omitempty"`
+	RouteTables           []RouteTableConfig           `yaml:"route_tables,omitempty"`
+	DHCPOptions           []DHCPOptionsConfig          `yaml:"dhcp_options,omitempty"`
 }
 
 type InstanceConfig struct {
-	Name         string   `yaml:"name"`
-	DisplayName  string   `yaml:"display_name"`
-	Shape        string   `yaml:"shape"`
-	SubnetID     string   `yaml:"subnet_id"`
-	ImageOCID    string   `yaml:"image_ocid"`
-	SSHPublicKey string   `yaml:"ssh_public_key"`
-	OCPUCount    *float64 `yaml:"ocpu_count"`
-	MemoryGB     *float64 `yaml:"memory_gb"`
+	Name           string   `yaml:"name"`
+	DisplayName    string   `yaml:"display_name"`
+	Shape          string   `yaml:"shape"`
+	Subnet         string   `yaml:"subnet,omitempty"`
+	SubnetID       string   `yaml:"subnet_id,omitempty"`
+	AssignPublicIP *bool    `yaml:"assign_public_ip,omitempty"`
+	ImageOCID      string   `yaml:"image_ocid"`
+	SSHPublicKey   string   `yaml:"ssh_public_key"`
+	OCPUCount      *float64 `yaml:"ocpu_count"`
+	MemoryGB       *float64 `yaml:"memory_gb"`
+	NSGs           []string `yaml:"nsgs,omitempty"`
+	// Image selects a platform image when ImageOCID is not set
+	Image *ImageConfig `yaml:"image,omitempty"`
+	// UserData is passed to cloud-init in addition to the SSH key
+	UserData *UserDataConfig `yaml:"user_data,omitempty"`
+	// BootVolumeSizeGB and BootVolumeVPUs override the size and performance of the boot volume
+	BootVolumeSizeGB int  `yaml:"boot_volume_size_gb,omitempty"`
+	BootVolumeVPUs   *int `yaml:"boot_volume_vpus_per_gb,omitempty"`
+	// Volumes are data volumes attached to the instance, kept when the instance is replaced
+	Volumes []BlockVolumeConfig `yaml:"volumes,omitempty"`
+	// BastionPlugin enables the Oracle Cloud Agent Bastion plugin required by managed SSH sessions
+	BastionPlugin bool `yaml:"bastion_plugin,omitempty"`
+	// AvailabilityDomain is a 1-based index into the availability domains of the region or an AD name,
+	// FaultDomain a 1-based index or a FAULT-DOMAIN-n name. Both override the spread policy of the fleet.
+	AvailabilityDomain string `yaml:"availability_domain,omitempty"`
+	FaultDomain        string `yaml:"fault_domain,omitempty"`
 }
 
+// ImageConfig selects the latest image of an operating system compatible with the instance shape,
+// optionally pinned to the build of a date or narrowed by a display name pattern
+type ImageConfig struct {
+	OperatingSystem string `yaml:"operating_system"`
+	Version         string `yaml:"version,omitempty"`
+	// DisplayName is a regular expression the image display name must match
+	DisplayName string `yaml:"display_name,omitempty"`
+	// Date pins the image built on a YYYY-MM-DD date instead of the latest
+	Date string `yaml:"date,omitempty"`
+	// AnyShape also lists images that are not compatible with the instance shape
+	AnyShape bool `yaml:"any_shape,omitempty"`
+}
+
+// Volume attachment types
+const (
+	AttachmentISCSI           = "iscsi"
+	AttachmentParavirtualized = "paravirtualized"
+)
+
+// Oracle defined volume backup policies, any other backup_policy is a policy OCID
+const (
+	BackupPolicyGold   = "gold"
+	BackupPolicySilver = "silver"
+	BackupPolicyBronze = "bronze"
+)
+
+// Volume size and performance limits. VPUs per GB go in steps of VolumeVPUsStep, 0 being the
+// lower cost level, 10 balanced and 20 or more higher performance. Boot volumes need at least 10.
+const (
+	MinVolumeSizeGB   = 50
+	MaxVolumeSizeGB   = 32768
+	MaxVolumeVPUs     = 120
+	MinBootVolumeVPUs = 10
+	VolumeVPUsStep    = 10
+)
+
+// VolumeDevicePrefix is the prefix of the consistent device paths of volumes, oraclevda being the boot volume
+const VolumeDevicePrefix = "/dev/oracleoci/oraclevd"
+
+// BlockVolumeConfig is a data volume of an instance
+type BlockVolumeConfig struct {
+	Name   string `yaml:"name"`
+	SizeGB int    `yaml:"size_gb"`
+	VPUs   *int   `yaml:"vpus_per_gb,omitempty"`
+	// Attachment is iscsi or paravirtualized, the default
+	Attachment string `yaml:"attachment,omitempty"`
+	// Device is a consistent device path such as /dev/oracleoci/oraclevdb
+	Device       string `yaml:"device,omitempty"`
+	BackupPolicy string `yaml:"backup_policy,omitempty"`
+}
+
+// ImageDateLayout is the layout of ImageConfig.Date
+const ImageDateLayout = "2006-01-02"
+
+// Spread policies placing the instances of a compute config round-robin
+const (
+	// SpreadNone places every instance in the first availability domain and lets OCI pick the fault domain
+	SpreadNone = "none"
+	// SpreadFaultDomains places every instance in the first availability domain, round-robin across its fault domains
+	SpreadFaultDomains = "fault-domains"
+	// SpreadAvailabilityDomains places instances round-robin across availability domains, then their fault domains
+	SpreadAvailabilityDomains = "availability-domains"
+)
+
+// FaultDomainsPerAD is the number of fault domains of every OCI availability domain
+const FaultDomainsPerAD = 3
+
 type ComputeConfig struct {
-	BaseConfig
-	Instances []InstanceConfig `yaml:"instances"`
+	BaseConfig `yaml:",inline"`
+	Instances  []InstanceConfig     `yaml:"instances"`
+	Pools      []InstancePoolConfig `yaml:"pools,omitempty"`
+	Spread     string               `yaml:"spread,omitempty"`
+}
+
+// InstancePoolConfig is a pool of identical instances launched from a template. AvailabilityDomains and
+// FaultDomains take indexes or names and restrict the placement, which otherwise follows the spread policy.
+type InstancePoolConfig struct {
+	Name                string         `yaml:"name"`
+	Size                int            `yaml:"size"`
+	Template            InstanceConfig `yaml:"template"`
+	AvailabilityDomains []string       `yaml:"availability_domains,omitempty"`
+	FaultDomains        []string       `yaml:"fault_domains,omitempty"`
+}
+
+// Bastion session types
+const (
+	SessionManagedSSH     = "managed-ssh"
+	SessionPortForwarding = "port-forwarding"
+)
+
+// Bastion session TTL limits in seconds as enforced by OCI
+const (
+	MinSessionTTLSeconds = 1800
+	MaxSessionTTLSeconds = 10800
+)
+
+// BastionSessionConfig describes a session opened ahead of time to a compute instance referenced by name.
+// Managed SSH sessions log in as username, port forwarding sessions forward to port.
+type BastionSessionConfig struct {
+	Name         string `yaml:"name"`
+	Type         string `yaml:"type"`
+	Instance     string `yaml:"instance"`
+	Username     string `yaml:"username,omitempty"`
+	Port         int    `yaml:"port,omitempty"`
+	SSHPublicKey string `yaml:"ssh_public_key"`
+	TTLSeconds   int    `yaml:"ttl_seconds,omitempty"`
 }
 
+// BastionConfig describes an OCI Bastion reaching the instances of a private subnet from the client CIDR
+// blocks. No bastion is created unless a subnet is set.
 type BastionConfig struct {
-	BaseConfig
+	BaseConfig           `yaml:",inline"`
+	Name                 string                 `yaml:"name,omitempty"`
+	Subnet               string                 `yaml:"subnet,omitempty"`
+	ClientCIDRs          []string               `yaml:"client_cidrs,omitempty"`
+	MaxSessionTTLSeconds int                    `yaml:"max_session_ttl_seconds,omitempty"`
+	Sessions             []BastionSessionConfig `yaml:"sessions,omitempty"`
+}
+
+// Enabled reports whether a bastion is described
+func (b BastionConfig) Enabled() bool {
+	return b.Subnet != ""
+}
+
+// MySQLBackupConfig describes the automatic backups of a DB system. Backups are enabled unless enabled is false.
+type MySQLBackupConfig struct {
+	Enabled         *bool  `yaml:"enabled,omitempty"`
+	RetentionDays   int    `yaml:"retention_days,omitempty"`
+	WindowStartTime string `yaml:"window_start_time,omitempty"`
+	PITR            bool   `yaml:"pitr,omitempty"`
+}
+
+// HeatwaveClusterConfig describes the HeatWave cluster attached to a DB system
+type HeatwaveClusterConfig struct {
+	Shape     string `yaml:"shape"`
+	NodeCount int    `yaml:"node_count"`
+	Lakehouse bool   `yaml:"lakehouse,omitempty"`
 }
 
+// HeatwaveConfig describes a MySQL HeatWave DB system in a subnet created by this program. The admin
+// password is read from the Pulumi secret named by admin_password_secret. No DB system is created
+// unless a subnet is set.
 type HeatwaveConfig struct {
-	BaseConfig
+	BaseConfig          `yaml:",inline"`
+	Name                string                 `yaml:"name,omitempty"`
+	Subnet              string                 `yaml:"subnet,omitempty"`
+	NSGs                []string               `yaml:"nsgs,omitempty"`
+	AvailabilityDomain  string                 `yaml:"availability_domain,omitempty"`
+	Shape               string                 `yaml:"shape,omitempty"`
+	MySQLVersion        string                 `yaml:"mysql_version,omitempty"`
+	StorageSizeGB       int                    `yaml:"storage_size_gb,omitempty"`
+	HostnameLabel       string                 `yaml:"hostname_label,omitempty"`
+	AdminUsername       string                 `yaml:"admin_username,omitempty"`
+	AdminPasswordSecret string                 `yaml:"admin_password_secret,omitempty"`
+	HighlyAvailable     bool                   `yaml:"highly_available,omitempty"`
+	MaintenanceWindow   string                 `yaml:"maintenance_window,omitempty"`
+	Backup              *MySQLBackupConfig     `yaml:"backup,omitempty"`
+	Cluster             *HeatwaveClusterConfig `yaml:"cluster,omitempty"`
+}
+
+// Enabled reports whether a DB system is described
+func (h HeatwaveConfig) Enabled() bool {
+	return h.Subnet != ""
 }
 
 type Config struct {
+	Identity IdentityConfig `yaml:"identity,omitempty"`
 	Network  NetworkConfig  `yaml:"network"`
 	Compute  ComputeConfig  `yaml:"compute"`
 	Bastion  BastionConfig  `yaml:"bastion"`
 	Heatwave HeatwaveConfig `yaml:"heatwave"`
+	Storage  StorageConfig  `yaml:"storage,omitempty"`
 }
 
+// LoadFromYaml loads a single config file in Strict mode
 func (c *Config) LoadFromYaml(path string) error {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return err
-	}
-	return yaml.Unmarshal(data, c)
+	return c.LoadLayered(path)
 }
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeMode controls how unknown keys are handled while loading config files
+type DecodeMode int
+
+const (
+	// Strict rejects keys that do not map to a config field
+	Strict DecodeMode = iota
+	// Lenient ignores unknown keys, which helps while migrating old config files
+	Lenient
+)
+
+// DecodeError describes a problem at a given position of a config file
+type DecodeError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e DecodeError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// DecodeErrors collects every decoding problem found in the loaded files
+type DecodeErrors []DecodeError
+
+func (e DecodeErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("failed to decode config (%d errors):\n  %s", len(e), strings.Join(msgs, "\n  "))
+}
+
+// nodeChecker walks a YAML node tree alongside the Go type it will be decoded into
+type nodeChecker struct {
+	file string
+	mode DecodeMode
+	errs DecodeErrors
+}
+
+func (c *nodeChecker) addf(node *yaml.Node, format string, args ...interface{}) {
+	c.errs = append(c.errs, DecodeError{
+		File:    c.file,
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// checkNode reports every key unknown to t (in Strict mode) and every value that cannot be decoded into t
+func checkNode(file string, mode DecodeMode, node *yaml.Node, t reflect.Type) DecodeErrors {
+	c := &nodeChecker{file: file, mode: mode}
+	c.check(node, t, "")
+	return c.errs
+}
+
+func (c *nodeChecker) check(node *yaml.Node, t reflect.Type, path string) {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			c.addf(node, "%s: expected a mapping, got %s", displayPath(path), kindName(node))
+			return
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := fields[key.Value]
+			if !ok {
+				if c.mode == Strict {
+					c.addf(key, "%s: unknown field %q", displayPath(path), key.Value)
+				}
+				continue
+			}
+			c.check(value, field, joinPath(path, key.Value))
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			c.addf(node, "%s: expected a list, got %s", displayPath(path), kindName(node))
+			return
+		}
+		for i, item := range node.Content {
+			c.check(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			c.addf(node, "%s: expected a mapping, got %s", displayPath(path), kindName(node))
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			c.check(node.Content[i+1], t.Elem(), joinPath(path, node.Content[i].Value))
+		}
+	default:
+		if node.Kind != yaml.ScalarNode {
+			c.addf(node, "%s: expected a %s value, got %s", displayPath(path), t.Kind(), kindName(node))
+			return
+		}
+		if err := node.Decode(reflect.New(t).Interface()); err != nil {
+			c.addf(node, "%s: cannot use %q as %s", displayPath(path), node.Value, t.Kind())
+		}
+	}
+}
+
+// yamlFields maps the YAML keys of a struct to their field types, following inline embedded structs
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+
+		tag := f.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(opts, "inline") {
+			for k, v := range yamlFields(f.Type) {
+				fields[k] = v
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
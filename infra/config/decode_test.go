@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadLayeredStrict(t *testing.T) {
+	dir := t.TempDir()
+	base := writeTestFile(t, dir, "base.yaml", `network:
+  compartment_id: "compartment-123"
+  cidr_blok: "10.0.0.0/16"
+  subnets:
+    - name: "public-subnet"
+      cidr_block: "10.0.1.0/24"
+`)
+	overlay := writeTestFile(t, dir, "dev/config.yaml", `compute:
+  instances:
+    - name: "instance-1"
+      ocpu_cout: 2
+      memory_gb: "lots"
+`)
+
+	var cfg Config
+	err := cfg.LoadLayered(base, overlay)
+
+	var derrs DecodeErrors
+	if !errors.As(err, &derrs) {
+		t.Fatalf("Expected DecodeErrors, but got %v", err)
+	}
+
+	expected := []DecodeError{
+		{File: base, Line: 3, Column: 3},
+		{File: overlay, Line: 4, Column: 7},
+		{File: overlay, Line: 5, Column: 18},
+	}
+	if len(derrs) != len(expected) {
+		t.Fatalf("Expected %d errors, but got %d: %v", len(expected), len(derrs), err)
+	}
+	for i, e := range expected {
+		if derrs[i].File != e.File || derrs[i].Line != e.Line || derrs[i].Column != e.Column {
+			t.Errorf("Expected error %d at %s:%d:%d, but got %v", i, e.File, e.Line, e.Column, derrs[i])
+		}
+	}
+}
+
+func TestLoadLayeredLenient(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, filepath.Join("dev", ConfigFileName), `network:
+  display_name: "dev-vcn"
+  legacy_field: true
+`)
+
+	var cfg Config
+	if err := cfg.LoadLayeredMode(Lenient, path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Network.DisplayName != "dev-vcn" {
+		t.Errorf("Expected display_name dev-vcn, but got %s", cfg.Network.DisplayName)
+	}
+
+	if err := cfg.LoadLayeredMode(Strict, path); err == nil {
+		t.Error("Expected strict mode to reject legacy_field")
+	}
+}
+
+func TestLoadLayeredLenientTypeErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "config.yaml", `network:
+  subnets: "public-subnet"
+`)
+
+	var cfg Config
+	if err := cfg.LoadLayeredMode(Lenient, path); err == nil {
+		t.Error("Expected lenient mode to still reject a scalar where a list is required")
+	}
+}
+
+func TestResolveDecodeMode(t *testing.T) {
+	tests := []struct {
+		name           string
+		configOverride string
+		envOverride    string
+		expected       DecodeMode
+	}{
+		{"Default is strict", "", "", Strict},
+		{"Config key enables lenient", "true", "", Lenient},
+		{"Env var enables lenient", "", "1", Lenient},
+		{"False stays strict", "false", "no", Strict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResolveDecodeMode(tt.configOverride, tt.envOverride)
+			if got != tt.expected {
+				t.Errorf("Expected mode %d, but got %d", tt.expected, got)
+			}
+		})
+	}
+}
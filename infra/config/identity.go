@@ -0,0 +1,174 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// CompartmentConfig describes a compartment and its child compartments. Names are unique across the
+// whole tree so that other sections can reference a compartment with compartment instead of compartment_id.
+type CompartmentConfig struct {
+	Name         string              `yaml:"name"`
+	Description  string              `yaml:"description"`
+	EnableDelete bool                `yaml:"enable_delete,omitempty"`
+	Compartments []CompartmentConfig `yaml:"compartments,omitempty"`
+}
+
+// IdentityConfig describes the compartment tree created below the root compartment of the tenancy
+// and the IAM groups, dynamic groups and policies of the tenancy
+type IdentityConfig struct {
+	TenancyID     string               `yaml:"tenancy_id"`
+	Compartments  []CompartmentConfig  `yaml:"compartments,omitempty"`
+	Groups        []GroupConfig        `yaml:"groups,omitempty"`
+	DynamicGroups []DynamicGroupConfig `yaml:"dynamic_groups,omitempty"`
+	Policies      []PolicyConfig       `yaml:"policies,omitempty"`
+}
+
+// CompartmentNames returns the names of every compartment in the tree
+func (i IdentityConfig) CompartmentNames() map[string]bool {
+	names := make(map[string]bool)
+	i.Walk(func(compartment CompartmentConfig, parent string) {
+		names[compartment.Name] = true
+	})
+	return names
+}
+
+// Walk calls fn for every compartment of the tree, parents before children, with the name of the
+// parent compartment or "" for top level compartments
+func (i IdentityConfig) Walk(fn func(compartment CompartmentConfig, parent string)) {
+	var walk func(compartments []CompartmentConfig, parent string)
+	walk = func(compartments []CompartmentConfig, parent string) {
+		for _, compartment := range compartments {
+			fn(compartment, parent)
+			walk(compartment.Compartments, compartment.Name)
+		}
+	}
+	walk(i.Compartments, "")
+}
+
+// CompartmentIDInput resolves the compartment of a section: the compartment created in this program
+// that compartment references by name, or the literal compartment_id
+func (b BaseConfig) CompartmentIDInput(ids map[string]pulumi.StringInput) (pulumi.StringInput, error) {
+	if b.Compartment == "" {
+		return pulumi.String(b.CompartmentID), nil
+	}
+
+	id, ok := ids[b.Compartment]
+	if !ok || id == nil {
+		return nil, fmt.Errorf("compartment %s not found", b.Compartment)
+	}
+	return id, nil
+}
+
+// GroupConfig describes an IAM group created in the root compartment of the tenancy
+type GroupConfig struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+}
+
+// TagMatchConfig matches resources carrying a defined tag, optionally with a given value
+type TagMatchConfig struct {
+	Namespace string `yaml:"namespace"`
+	Key       string `yaml:"key"`
+	Value     string `yaml:"value,omitempty"`
+}
+
+// DynamicGroupMatchConfig generates the matching rule of a dynamic group from the compartments and
+// defined tags of instances. Conditions are combined with ANY unless all is set.
+type DynamicGroupMatchConfig struct {
+	Compartments   []string         `yaml:"compartments,omitempty"`
+	CompartmentIDs []string         `yaml:"compartment_ids,omitempty"`
+	Tags           []TagMatchConfig `yaml:"tags,omitempty"`
+	All            bool             `yaml:"all,omitempty"`
+}
+
+// DynamicGroupConfig describes a dynamic group with either a literal matching rule or a generated one
+type DynamicGroupConfig struct {
+	Name         string                   `yaml:"name"`
+	Description  string                   `yaml:"description"`
+	MatchingRule string                   `yaml:"matching_rule,omitempty"`
+	Match        *DynamicGroupMatchConfig `yaml:"match,omitempty"`
+}
+
+// PolicyConfig describes a policy attached to a compartment of the tree, or to the tenancy when
+// compartment is empty. Statements are templates, see IdentityConfig.PolicyStatements.
+type PolicyConfig struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Compartment string   `yaml:"compartment,omitempty"`
+	Statements  []string `yaml:"statements"`
+}
+
+// MatchingRule renders the matching rule of a dynamic group given the OCIDs of the matched compartments,
+// e.g. ANY {instance.compartment.id = 'ocid1...', tag.ops.role.value = 'web'}
+func (m DynamicGroupMatchConfig) MatchingRule(compartmentIDs []string) string {
+	var conditions []string
+	for _, id := range compartmentIDs {
+		conditions = append(conditions, fmt.Sprintf("instance.compartment.id = '%s'", id))
+	}
+	for _, tag := range m.Tags {
+		if tag.Value == "" {
+			conditions = append(conditions, fmt.Sprintf("tag.%s.%s.value", tag.Namespace, tag.Key))
+			continue
+		}
+		conditions = append(conditions, fmt.Sprintf("tag.%s.%s.value = '%s'", tag.Namespace, tag.Key, tag.Value))
+	}
+
+	keyword := "ANY"
+	if m.All {
+		keyword = "ALL"
+	}
+	return fmt.Sprintf("%s {%s}", keyword, strings.Join(conditions, ", "))
+}
+
+// CompartmentPath returns the path of a compartment relative to the compartment named from, or to the
+// tenancy when from is empty, as used in policy statements, e.g. infra:infra-network
+func (i IdentityConfig) CompartmentPath(from, name string) (string, error) {
+	parents := make(map[string]string)
+	i.Walk(func(compartment CompartmentConfig, parent string) {
+		parents[compartment.Name] = parent
+	})
+	if _, ok := parents[name]; !ok {
+		return "", fmt.Errorf("compartment %s not found", name)
+	}
+
+	var path []string
+	for current := name; current != from; current = parents[current] {
+		if current == "" {
+			return "", fmt.Errorf("compartment %s is not below compartment %s", name, from)
+		}
+		path = append([]string{current}, path...)
+	}
+	if len(path) == 0 {
+		return "", fmt.Errorf("compartment %s cannot reference itself", name)
+	}
+	return strings.Join(path, ":"), nil
+}
+
+// PolicyStatements renders the statements of a policy. Statements are text/template templates where
+// {{ compartment "name" }} expands to the path of a compartment of the tree relative to the compartment
+// the policy is attached to.
+func (i IdentityConfig) PolicyStatements(policy PolicyConfig) ([]string, error) {
+	funcs := template.FuncMap{
+		"compartment": func(name string) (string, error) {
+			return i.CompartmentPath(policy.Compartment, name)
+		},
+	}
+
+	statements := make([]string, 0, len(policy.Statements))
+	for j, statement := range policy.Statements {
+		tmpl, err := template.New(fmt.Sprintf("%s[%d]", policy.Name, j)).Funcs(funcs).Option("missingkey=error").Parse(statement)
+		if err != nil {
+			return nil, err
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, nil); err != nil {
+			return nil, err
+		}
+		statements = append(statements, b.String())
+	}
+	return statements, nil
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+func TestIdentityWalk(t *testing.T) {
+	identity := IdentityConfig{
+		Compartments: []CompartmentConfig{
+			{Name: "infra", Compartments: []CompartmentConfig{{Name: "network"}, {Name: "compute"}}},
+			{Name: "sandbox"},
+		},
+	}
+
+	var visited []string
+	identity.Walk(func(compartment CompartmentConfig, parent string) {
+		visited = append(visited, parent+"/"+compartment.Name)
+	})
+
+	expected := []string{"/infra", "infra/network", "infra/compute", "/sandbox"}
+	if len(visited) != len(expected) {
+		t.Fatalf("Expected %v, but got %v", expected, visited)
+	}
+	for i := range expected {
+		if visited[i] != expected[i] {
+			t.Errorf("Expected %s at %d, but got %s", expected[i], i, visited[i])
+		}
+	}
+
+	if names := identity.CompartmentNames(); len(names) != 4 || !names["compute"] {
+		t.Errorf("Expected 4 compartment names, but got %v", names)
+	}
+}
+
+func TestCompartmentIDInput(t *testing.T) {
+	ids := map[string]pulumi.StringInput{"network": pulumi.String("ocid1.compartment.oc1..network")}
+
+	tests := []struct {
+		name          string
+		base          BaseConfig
+		expected      pulumi.StringInput
+		expectedError bool
+	}{
+		{"Literal compartment_id", BaseConfig{CompartmentID: "ocid1.compartment.oc1..literal"}, pulumi.String("ocid1.compartment.oc1..literal"), false},
+		{"Compartment by name", BaseConfig{Compartment: "network"}, pulumi.String("ocid1.compartment.oc1..network"), false},
+		{"Unknown compartment", BaseConfig{Compartment: "compute"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.base.CompartmentIDInput(ids)
+			if tt.expectedError {
+				if err == nil {
+					t.Errorf("Expected error but got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %v, but got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPolicyStatements(t *testing.T) {
+	identity := IdentityConfig{
+		Compartments: []CompartmentConfig{
+			{Name: "infra", Compartments: []CompartmentConfig{{Name: "network", Compartments: []CompartmentConfig{{Name: "edge"}}}}},
+			{Name: "sandbox"},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		compartment   string
+		statement     string
+		expected      string
+		expectedError bool
+	}{
+		{"Path from tenancy", "", `Allow group A to read all-resources in compartment {{ compartment "edge" }}`, "Allow group A to read all-resources in compartment infra:network:edge", false},
+		{"Path from parent", "infra", `Allow group A to read all-resources in compartment {{ compartment "edge" }}`, "Allow group A to read all-resources in compartment network:edge", false},
+		{"Plain statement", "infra", "Allow group A to read all-resources in tenancy", "Allow group A to read all-resources in tenancy", false},
+		{"Outside the policy compartment", "infra", `Allow group A to read all-resources in compartment {{ compartment "sandbox" }}`, "", true},
+		{"Policy compartment itself", "infra", `Allow group A to read all-resources in compartment {{ compartment "infra" }}`, "", true},
+		{"Unknown compartment", "", `Allow group A to read all-resources in compartment {{ compartment "missing" }}`, "", true},
+		{"Invalid template", "", `Allow group A to read all-resources in compartment {{ compartment "edge" }`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := identity.PolicyStatements(PolicyConfig{Name: "policy", Compartment: tt.compartment, Statements: []string{tt.statement}})
+			if tt.expectedError {
+				if err == nil {
+					t.Errorf("Expected error but got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(got) != 1 || got[0] != tt.expected {
+				t.Errorf("Expected %q, but got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDynamicGroupMatchingRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		match    DynamicGroupMatchConfig
+		ids      []string
+		expected string
+	}{
+		{
+			name:     "Compartments",
+			match:    DynamicGroupMatchConfig{},
+			ids:      []string{"ocid1.compartment.oc1..a", "ocid1.compartment.oc1..b"},
+			expected: "ANY {instance.compartment.id = 'ocid1.compartment.oc1..a', instance.compartment.id = 'ocid1.compartment.oc1..b'}",
+		},
+		{
+			name:     "Compartment and tags",
+			match:    DynamicGroupMatchConfig{All: true, Tags: []TagMatchConfig{{Namespace: "ops", Key: "role", Value: "web"}, {Namespace: "ops", Key: "managed"}}},
+			ids:      []string{"ocid1.compartment.oc1..a"},
+			expected: "ALL {instance.compartment.id = 'ocid1.compartment.oc1..a', tag.ops.role.value = 'web', tag.ops.managed.value}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.match.MatchingRule(tt.ids); got != tt.expected {
+				t.Errorf("Expected %q, but got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mergeKeys are the fields used to match list entries between layers, in order of preference
+var mergeKeys = []string{"name", "display_name"}
+
+// LoadLayered loads the given files in order in Strict mode, deep-merging every file on top of the
+// previous ones. Maps are merged key by key, lists of objects are merged by their name/display_name
+// and any other value is replaced by the later file.
+func (c *Config) LoadLayered(paths ...string) error {
+	return c.LoadLayeredMode(Strict, paths...)
+}
+
+// LoadLayeredMode is LoadLayered with an explicit DecodeMode. Every file is checked on its own
+// before merging so that decoding errors point at the file, line and column they come from.
+func (c *Config) LoadLayeredMode(mode DecodeMode, paths ...string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("at least one config file is required")
+	}
+
+	var merged *yaml.Node
+	var errs DecodeErrors
+	for _, path := range paths {
+		node, err := readYamlNode(path)
+		if err != nil {
+			return err
+		}
+		if node == nil {
+			continue
+		}
+
+		errs = append(errs, checkNode(path, mode, node, reflect.TypeOf(*c))...)
+		if len(errs) > 0 {
+			continue
+		}
+
+		if merged == nil {
+			merged = node
+			continue
+		}
+		if err := mergeNodes(merged, node); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	if merged == nil {
+		return nil
+	}
+	return merged.Decode(c)
+}
+
+// readYamlNode parses a file and returns its top level node, or nil for an empty document
+func readYamlNode(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind == yaml.ScalarNode && root.Tag == "!!null" {
+		return nil, nil
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: top level must be a mapping", path)
+	}
+
+	return root, nil
+}
+
+// mergeNodes merges overlay into base in place
+func mergeNodes(base, overlay *yaml.Node) error {
+	if base.Kind != yaml.MappingNode || overlay.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: cannot merge %s into %s", overlay.Line, kindName(overlay), kindName(base))
+	}
+
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		key, value := overlay.Content[i], overlay.Content[i+1]
+		existing := mappingValue(base, key.Value)
+		switch {
+		case existing == nil:
+			base.Content = append(base.Content, key, value)
+		case existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			if err := mergeNodes(existing, value); err != nil {
+				return fmt.Errorf("%s: %w", key.Value, err)
+			}
+		case existing.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode:
+			if err := mergeSequences(existing, value); err != nil {
+				return fmt.Errorf("%s: %w", key.Value, err)
+			}
+		default:
+			*existing = *value
+		}
+	}
+
+	return nil
+}
+
+// mergeSequences merges lists of objects by their merge key and replaces any other list
+func mergeSequences(base, overlay *yaml.Node) error {
+	if !isKeyedSequence(base) || !isKeyedSequence(overlay) {
+		*base = *overlay
+		return nil
+	}
+
+	for _, item := range overlay.Content {
+		key := sequenceItemKey(item)
+		existing := findSequenceItem(base, key)
+		if existing == nil {
+			base.Content = append(base.Content, item)
+			continue
+		}
+		if err := mergeNodes(existing, item); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// isKeyedSequence reports whether every item of a list is an object carrying a merge key
+func isKeyedSequence(node *yaml.Node) bool {
+	for _, item := range node.Content {
+		if item.Kind != yaml.MappingNode || sequenceItemKey(item) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// sequenceItemKey returns the value of the first merge key present on a list item
+func sequenceItemKey(item *yaml.Node) string {
+	for _, k := range mergeKeys {
+		if v := mappingValue(item, k); v != nil && v.Kind == yaml.ScalarNode && v.Value != "" {
+			return v.Value
+		}
+	}
+	return ""
+}
+
+// findSequenceItem returns the list item with the given merge key
+func findSequenceItem(seq *yaml.Node, key string) *yaml.Node {
+	for _, item := range seq.Content {
+		if sequenceItemKey(item) == key {
+			return item
+		}
+	}
+	return nil
+}
+
+// mappingValue returns the value node stored under key in a mapping node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func kindName(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.SequenceNode:
+		return "list"
+	case yaml.ScalarNode:
+		return "scalar"
+	case yaml.AliasNode:
+		return "alias"
+	default:
+		return "document"
+	}
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestFile writes content to name inside dir and returns the full path
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadLayered(t *testing.T) {
+	dir := t.TempDir()
+	base := writeTestFile(t, dir, "base.yaml", `
+network:
+  compartment_id: "compartment-base"
+  cidr_block: "10.0.0.0/16"
+  display_name: "base-vcn"
+  subnets:
+    - name: "public-subnet"
+      cidr_block: "10.0.1.0/24"
+    - name: "private-subnet"
+      cidr_block: "10.0.2.0/24"
+  security_lists:
+    - display_name: "public-ingress"
+      protocol: "6"
+      source: "0.0.0.0/0"
+      tcp_options:
+        - min_port: 22
+          max_port: 22
+compute:
+  compartment_id: "compartment-base"
+  instances:
+    - name: "instance-1"
+      shape: "VM.Standard.E4.Flex"
+      ocpu_count: 2.0
+`)
+	overlay := writeTestFile(t, dir, "prod/config.yaml", `
+network:
+  display_name: "prod-vcn"
+  subnets:
+    - name: "private-subnet"
+      cidr_block: "10.0.20.0/24"
+    - name: "database-subnet"
+      cidr_block: "10.0.3.0/24"
+  security_lists:
+    - display_name: "public-ingress"
+      tcp_options:
+        - min_port: 443
+          max_port: 443
+compute:
+  instances:
+    - name: "instance-1"
+      ocpu_count: 4.0
+    - name: "instance-2"
+      shape: "VM.Standard.E4.Flex"
+`)
+
+	var cfg Config
+	if err := cfg.LoadLayered(base, overlay); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if cfg.Network.DisplayName != "prod-vcn" {
+		t.Errorf("Expected display_name prod-vcn, but got %s", cfg.Network.DisplayName)
+	}
+	if cfg.Network.CompartmentID != "compartment-base" {
+		t.Errorf("Expected compartment_id from base, but got %s", cfg.Network.CompartmentID)
+	}
+
+	expectedSubnets := []SubnetConfig{
+		{Name: "public-subnet", CidrBlock: "10.0.1.0/24"},
+		{Name: "private-subnet", CidrBlock: "10.0.20.0/24"},
+		{Name: "database-subnet", CidrBlock: "10.0.3.0/24"},
+	}
+	if len(cfg.Network.Subnets) != len(expectedSubnets) {
+		t.Fatalf("Expected %d subnets, but got %d", len(expectedSubnets), len(cfg.Network.Subnets))
+	}
+	for i, expected := range expectedSubnets {
+		if cfg.Network.Subnets[i] != expected {
+			t.Errorf("Expected subnet %d to be %+v, but got %+v", i, expected, cfg.Network.Subnets[i])
+		}
+	}
+
+	// Lists of scalars or unnamed objects are replaced rather than merged
+	seclist := cfg.Network.SecurityLists[0]
+	if seclist.Source != "0.0.0.0/0" || seclist.Protocol != "6" {
+		t.Errorf("Expected security list fields from base to be kept, but got %+v", seclist)
+	}
+	if len(seclist.TCPOptions) != 1 || seclist.TCPOptions[0].MinPort != 443 {
+		t.Errorf("Expected tcp_options to be replaced by overlay, but got %+v", seclist.TCPOptions)
+	}
+
+	if len(cfg.Compute.Instances) != 2 {
+		t.Fatalf("Expected 2 instances, but got %d", len(cfg.Compute.Instances))
+	}
+	first := cfg.Compute.Instances[0]
+	if first.Shape != "VM.Standard.E4.Flex" || first.OCPUCount == nil || *first.OCPUCount != 4.0 {
+		t.Errorf("Expected instance-1 to keep its shape and take the overlay ocpu_count, but got %+v", first)
+	}
+}
+
+func TestLoadLayeredErrors(t *testing.T) {
+	dir := t.TempDir()
+	base := writeTestFile(t, dir, "base.yaml", "network:\n  subnets:\n    - name: \"a\"\n")
+	conflicting := writeTestFile(t, dir, "conflict.yaml", "network: \"not-a-mapping\"\n")
+	list := writeTestFile(t, dir, "list.yaml", "- name: \"a\"\n")
+	empty := writeTestFile(t, dir, "empty.yaml", "---\n")
+
+	tests := []struct {
+		name          string
+		paths         []string
+		expectedError bool
+	}{
+		{"No files", nil, true},
+		{"Missing file", []string{filepath.Join(dir, "missing.yaml")}, true},
+		{"Top level list", []string{list}, true},
+		{"Scalar cannot decode as network", []string{base, conflicting}, true},
+		{"Empty overlay", []string{base, empty}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			err := cfg.LoadLayered(tt.paths...)
+			if tt.expectedError && err == nil {
+				t.Errorf("Expected error but got none")
+			}
+			if !tt.expectedError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestLayerPaths(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, filepath.Join("dev", ConfigFileName), "---\n")
+
+	paths, err := LayerPaths(dir, "dev")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(paths) != 1 {
+		t.Errorf("Expected only the environment file without a base, but got %v", paths)
+	}
+
+	writeTestFile(t, dir, BaseConfigFileName, "---\n")
+	paths, err = LayerPaths(dir, "dev")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(paths) != 2 || filepath.Base(paths[0]) != BaseConfigFileName {
+		t.Errorf("Expected base file followed by environment file, but got %v", paths)
+	}
+}
+
+func TestRepositoryEnvironments(t *testing.T) {
+	for _, env := range AvailableEnvironments(".") {
+		t.Run(env, func(t *testing.T) {
+			paths, err := LayerPaths(".", env)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var cfg Config
+			if err := cfg.LoadLayered(paths...); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(cfg.Network.Subnets) == 0 || len(cfg.Network.SecurityLists) == 0 {
+				t.Errorf("Expected shared network settings from %s", BaseConfigFileName)
+			}
+			if len(cfg.Compute.Instances) == 0 {
+				t.Errorf("Expected instances for environment %s", env)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// policyVerbs are the verbs of the OCI policy language, from least to most privileged
+var policyVerbs = map[string]bool{"inspect": true, "read": true, "use": true, "manage": true}
+
+// policyParser walks the whitespace separated tokens of a policy statement
+type policyParser struct {
+	tokens []string
+	pos    int
+}
+
+// peek returns the lower cased current token or "" at the end of the statement
+func (p *policyParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return strings.ToLower(p.tokens[p.pos])
+}
+
+// next consumes and returns the lower cased current token
+func (p *policyParser) next() string {
+	token := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+	return token
+}
+
+// expect consumes the keyword or reports what was found instead
+func (p *policyParser) expect(keyword string) error {
+	if token := p.next(); token != keyword {
+		if token == "" {
+			return fmt.Errorf("expected %q but the statement ended", keyword)
+		}
+		return fmt.Errorf("expected %q but got %q", keyword, token)
+	}
+	return nil
+}
+
+// until consumes tokens up to the keyword, which is not consumed, and returns how many were consumed
+func (p *policyParser) until(keyword string) int {
+	start := p.pos
+	for p.pos < len(p.tokens) && p.peek() != keyword {
+		p.pos++
+	}
+	return p.pos - start
+}
+
+// subject parses the subject of an allow, endorse or admit statement, e.g. group Admins, OtherGroup
+func (p *policyParser) subject(end string) error {
+	switch kind := p.next(); kind {
+	case "any-user", "any-group":
+	case "group", "dynamic-group", "service":
+		if p.until(end) == 0 {
+			return fmt.Errorf("%s requires a name", kind)
+		}
+	case "":
+		return fmt.Errorf("subject is required")
+	default:
+		return fmt.Errorf("unknown subject %q, expected group, dynamic-group, service, any-user or any-group", kind)
+	}
+	if p.until(end) != 0 {
+		return fmt.Errorf("unexpected %q after subject", p.tokens[p.pos-1])
+	}
+	return p.expect(end)
+}
+
+// access parses a verb followed by a resource type, or a list of permissions in braces
+func (p *policyParser) access() error {
+	token := p.peek()
+	if strings.HasPrefix(token, "{") {
+		for !strings.HasSuffix(p.next(), "}") {
+			if p.peek() == "" {
+				return fmt.Errorf("unterminated permission list")
+			}
+		}
+		return nil
+	}
+
+	if !policyVerbs[p.next()] {
+		if token == "" {
+			return fmt.Errorf("verb is required")
+		}
+		return fmt.Errorf("unknown verb %q, expected inspect, read, use or manage", token)
+	}
+	if p.until("in") == 0 {
+		return fmt.Errorf("resource type is required after %q", token)
+	}
+	return nil
+}
+
+// location parses tenancy, compartment <name> or compartment id <ocid>
+func (p *policyParser) location() error {
+	switch kind := p.next(); kind {
+	case "tenancy":
+	case "compartment":
+		if p.peek() == "id" {
+			p.next()
+		}
+		if name := p.next(); name == "" || name == "where" {
+			return fmt.Errorf("compartment requires a name or id")
+		}
+	default:
+		return fmt.Errorf("expected tenancy or compartment after \"in\" but got %q", kind)
+	}
+	return nil
+}
+
+// conditions parses the optional where clause that ends a statement
+func (p *policyParser) conditions() error {
+	switch p.next() {
+	case "":
+		return nil
+	case "where":
+		if p.peek() == "" {
+			return fmt.Errorf("where requires a condition")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unexpected %q, expected where or the end of the statement", p.tokens[p.pos-1])
+	}
+}
+
+// ValidatePolicyStatement checks the basic syntax of an OCI policy statement: Allow, Endorse, Admit
+// and Define statements with their subject, verb or permissions, resource type, location and an
+// optional where clause. Resource types, permissions and conditions are not checked.
+func ValidatePolicyStatement(statement string) error {
+	p := &policyParser{tokens: strings.Fields(statement)}
+
+	switch p.next() {
+	case "allow":
+		if err := p.subject("to"); err != nil {
+			return err
+		}
+		if err := p.access(); err != nil {
+			return err
+		}
+		if err := p.expect("in"); err != nil {
+			return err
+		}
+		if err := p.location(); err != nil {
+			return err
+		}
+	case "endorse":
+		if err := p.subject("to"); err != nil {
+			return err
+		}
+		if err := p.access(); err != nil {
+			return err
+		}
+		if err := p.expect("in"); err != nil {
+			return err
+		}
+		switch p.next() {
+		case "any-tenancy":
+		case "tenancy":
+			if p.next() == "" {
+				return fmt.Errorf("tenancy requires an alias")
+			}
+		default:
+			return fmt.Errorf("expected tenancy or any-tenancy after \"in\"")
+		}
+	case "admit":
+		if err := p.subject("of"); err != nil {
+			return err
+		}
+		if err := p.expect("tenancy"); err != nil {
+			return err
+		}
+		if p.next() == "" {
+			return fmt.Errorf("tenancy requires an alias")
+		}
+		if err := p.expect("to"); err != nil {
+			return err
+		}
+		if err := p.access(); err != nil {
+			return err
+		}
+		if err := p.expect("in"); err != nil {
+			return err
+		}
+		if err := p.location(); err != nil {
+			return err
+		}
+	case "define":
+		switch kind := p.next(); kind {
+		case "tenancy", "group", "dynamic-group":
+		default:
+			return fmt.Errorf("expected tenancy, group or dynamic-group after \"define\" but got %q", kind)
+		}
+		if p.next() == "" {
+			return fmt.Errorf("define requires an alias")
+		}
+		if err := p.expect("as"); err != nil {
+			return err
+		}
+		if ocid := p.next(); !strings.HasPrefix(ocid, "ocid1.") {
+			return fmt.Errorf("define requires an OCID but got %q", ocid)
+		}
+		if p.peek() != "" {
+			return fmt.Errorf("unexpected %q after the OCID", p.tokens[p.pos])
+		}
+		return nil
+	case "":
+		return fmt.Errorf("statement is empty")
+	default:
+		return fmt.Errorf("statement must start with allow, endorse, admit or define")
+	}
+
+	return p.conditions()
+}
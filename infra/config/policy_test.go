@@ -0,0 +1,47 @@
+package config
+
+import "testing"
+
+func TestValidatePolicyStatement(t *testing.T) {
+	tests := []struct {
+		name          string
+		statement     string
+		expectedError bool
+	}{
+		{"Allow group in tenancy", "Allow group Admins to manage all-resources in tenancy", false},
+		{"Allow several groups", "Allow group NetworkAdmins, SecurityAdmins to use virtual-network-family in compartment infra:infra-network", false},
+		{"Allow dynamic group with condition", "allow dynamic-group instances to manage objects in compartment infra where target.bucket.name = 'my-bucket'", false},
+		{"Allow permissions", "Allow group Readers to {BUCKET_READ, OBJECT_READ} in compartment id ocid1.compartment.oc1..example", false},
+		{"Allow any-user", "Allow any-user to read buckets in tenancy where request.principal.type = 'instance'", false},
+		{"Allow service", "Allow service objectstorage-eu-frankfurt-1 to manage object-family in tenancy", false},
+		{"Define tenancy", "Define tenancy Partner as ocid1.tenancy.oc1..partner", false},
+		{"Endorse", "Endorse group Admins to manage object-family in tenancy Partner", false},
+		{"Admit", "Admit group Auditors of tenancy Partner to read all-resources in tenancy", false},
+		{"Empty statement", "  ", true},
+		{"Unknown keyword", "Permit group Admins to manage all-resources in tenancy", true},
+		{"Missing subject name", "Allow group to manage all-resources in tenancy", true},
+		{"Unknown subject", "Allow user bob to manage all-resources in tenancy", true},
+		{"Unknown verb", "Allow group Admins to administer all-resources in tenancy", true},
+		{"Missing resource type", "Allow group Admins to manage in tenancy", true},
+		{"Missing location", "Allow group Admins to manage all-resources", true},
+		{"Unknown location", "Allow group Admins to manage all-resources in region", true},
+		{"Missing compartment name", "Allow group Admins to manage all-resources in compartment", true},
+		{"Empty where", "Allow group Admins to manage all-resources in tenancy where", true},
+		{"Trailing tokens", "Allow group Admins to manage all-resources in tenancy now", true},
+		{"Unterminated permissions", "Allow group Readers to {BUCKET_READ, OBJECT_READ in tenancy", true},
+		{"Define without OCID", "Define tenancy Partner as partner", true},
+		{"Admit without tenancy", "Admit group Auditors of Partner to read all-resources in tenancy", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePolicyStatement(tt.statement)
+			if tt.expectedError && err == nil {
+				t.Errorf("Expected error for %q but got none", tt.statement)
+			}
+			if !tt.expectedError && err != nil {
+				t.Errorf("Expected no error for %q but got %v", tt.statement, err)
+			}
+		})
+	}
+}
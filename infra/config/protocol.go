@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// IP protocol numbers as expected by OCI security rules
+const (
+	ProtocolAll    = "all"
+	ProtocolICMP   = "1"
+	ProtocolTCP    = "6"
+	ProtocolUDP    = "17"
+	ProtocolICMPv6 = "58"
+)
+
+var protocolNames = map[string]string{
+	"all":    ProtocolAll,
+	"icmp":   ProtocolICMP,
+	"tcp":    ProtocolTCP,
+	"udp":    ProtocolUDP,
+	"icmpv6": ProtocolICMPv6,
+}
+
+// ProtocolNumber translates a symbolic protocol name (tcp, udp, icmp, icmpv6, all) or an
+// IP protocol number into the value OCI expects
+func ProtocolNumber(protocol string) (string, error) {
+	if number, ok := protocolNames[strings.ToLower(protocol)]; ok {
+		return number, nil
+	}
+
+	n, err := strconv.Atoi(protocol)
+	if err != nil || n < 0 || n > 255 {
+		return "", fmt.Errorf("%q is not a valid protocol (tcp, udp, icmp, icmpv6, all or 0-255)", protocol)
+	}
+
+	return strconv.Itoa(n), nil
+}
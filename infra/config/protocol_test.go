@@ -0,0 +1,41 @@
+package config
+
+import "testing"
+
+func TestProtocolNumber(t *testing.T) {
+	tests := []struct {
+		protocol      string
+		expected      string
+		expectedError bool
+	}{
+		{"tcp", ProtocolTCP, false},
+		{"UDP", ProtocolUDP, false},
+		{"icmp", ProtocolICMP, false},
+		{"icmpv6", ProtocolICMPv6, false},
+		{"all", ProtocolAll, false},
+		{"6", ProtocolTCP, false},
+		{"47", "47", false},
+		{"256", "", true},
+		{"-1", "", true},
+		{"sctp", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.protocol, func(t *testing.T) {
+			got, err := ProtocolNumber(tt.protocol)
+			if tt.expectedError {
+				if err == nil {
+					t.Errorf("Expected error but got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected protocol %s, but got %s", tt.expected, got)
+			}
+		})
+	}
+}
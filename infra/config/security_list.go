@@ -0,0 +1,78 @@
+package config
+
+// MaxSecurityListsPerSubnet is the number of security lists OCI allows on a single subnet
+const MaxSecurityListsPerSubnet = 5
+
+// IsFlat reports whether the security list uses the flat format of a single rule
+func (s SecurityListConfig) IsFlat() bool {
+	return s.Protocol != ""
+}
+
+// Rules returns the ingress and egress rules of a security list, converting the flat format
+func (s SecurityListConfig) Rules() ([]SecurityRuleConfig, []SecurityRuleConfig) {
+	ingress := append([]SecurityRuleConfig(nil), s.IngressRules...)
+	egress := append([]SecurityRuleConfig(nil), s.EgressRules...)
+	if !s.IsFlat() {
+		return ingress, egress
+	}
+
+	rule := SecurityRuleConfig{
+		Protocol:    s.Protocol,
+		Description: s.Description,
+		Stateless:   s.Stateless,
+		TCPOptions:  s.TCPOptions,
+		UDPOptions:  s.UDPOptions,
+		ICMPOptions: s.ICMPOptions,
+	}
+	if sources := s.SourceCIDRs(); len(sources) > 0 {
+		ingressRule := rule
+		ingressRule.Source, ingressRule.Sources = sources[0], sources[1:]
+		ingress = append(ingress, ingressRule)
+	}
+	if destinations := s.DestinationCIDRs(); len(destinations) > 0 {
+		egressRule := rule
+		egressRule.Destination, egressRule.Destinations = destinations[0], destinations[1:]
+		egress = append(egress, egressRule)
+	}
+	return ingress, egress
+}
+
+// GroupedSecurityListName is the name of the security list that flat entries of a subnet are merged into
+func GroupedSecurityListName(subnetName string) string {
+	return subnetName + "-security-list"
+}
+
+// GroupedSecurityLists returns the security lists in the grouped format. Flat entries attached to
+// the same subnet are merged into a single list named by GroupedSecurityListName, in the position
+// of the first of them. Flat entries without a subnet_name keep their display name.
+func (n NetworkConfig) GroupedSecurityLists() []SecurityListConfig {
+	var grouped []SecurityListConfig
+	merged := make(map[string]int)
+
+	for _, seclist := range n.SecurityLists {
+		ingress, egress := seclist.Rules()
+		if !seclist.IsFlat() || seclist.SubnetName == "" {
+			grouped = append(grouped, SecurityListConfig{
+				DisplayName:  seclist.DisplayName,
+				SubnetName:   seclist.SubnetName,
+				IngressRules: ingress,
+				EgressRules:  egress,
+			})
+			continue
+		}
+
+		i, ok := merged[seclist.SubnetName]
+		if !ok {
+			i = len(grouped)
+			merged[seclist.SubnetName] = i
+			grouped = append(grouped, SecurityListConfig{
+				DisplayName: GroupedSecurityListName(seclist.SubnetName),
+				SubnetName:  seclist.SubnetName,
+			})
+		}
+		grouped[i].IngressRules = append(grouped[i].IngressRules, ingress...)
+		grouped[i].EgressRules = append(grouped[i].EgressRules, egress...)
+	}
+
+	return grouped
+}
@@ -0,0 +1,72 @@
+package config
+
+import "testing"
+
+func TestGroupedSecurityLists(t *testing.T) {
+	n := NetworkConfig{
+		SecurityLists: []SecurityListConfig{
+			{DisplayName: "public-ingress", SubnetName: "public-subnet", Protocol: "tcp", Source: "0.0.0.0/0", TCPOptions: []TCPOptionConfig{{MinPort: 22, MaxPort: 22}}},
+			{DisplayName: "standalone", Protocol: "all", Destination: "0.0.0.0/0"},
+			{DisplayName: "public-egress", SubnetName: "public-subnet", Protocol: "all", Source: "null", Destination: "0.0.0.0/0"},
+			{
+				DisplayName:  "private-security-list",
+				SubnetName:   "private-subnet",
+				IngressRules: []SecurityRuleConfig{{Protocol: "tcp", Source: "10.0.1.0/24"}},
+			},
+		},
+	}
+
+	grouped := n.GroupedSecurityLists()
+
+	expected := []struct {
+		name    string
+		subnet  string
+		ingress int
+		egress  int
+	}{
+		{"public-subnet-security-list", "public-subnet", 1, 1},
+		{"standalone", "", 0, 1},
+		{"private-security-list", "private-subnet", 1, 0},
+	}
+	if len(grouped) != len(expected) {
+		t.Fatalf("Expected %d security lists, but got %d: %+v", len(expected), len(grouped), grouped)
+	}
+	for i, e := range expected {
+		got := grouped[i]
+		if got.DisplayName != e.name || got.SubnetName != e.subnet {
+			t.Errorf("Expected security list %d to be %s on %q, but got %s on %q", i, e.name, e.subnet, got.DisplayName, got.SubnetName)
+		}
+		if len(got.IngressRules) != e.ingress || len(got.EgressRules) != e.egress {
+			t.Errorf("Expected %s to have %d ingress and %d egress rules, but got %d and %d",
+				e.name, e.ingress, e.egress, len(got.IngressRules), len(got.EgressRules))
+		}
+		if got.IsFlat() {
+			t.Errorf("Expected %s to be migrated to the grouped format", e.name)
+		}
+	}
+
+	if ports := grouped[0].IngressRules[0].TCPOptions; len(ports) != 1 || ports[0].MinPort != 22 {
+		t.Errorf("Expected migrated ingress rule to keep port 22, but got %+v", ports)
+	}
+}
+
+func TestSecurityListRulesFlatWithBothEndpoints(t *testing.T) {
+	seclist := SecurityListConfig{
+		Protocol:    "udp",
+		Source:      "10.0.0.0/16",
+		Sources:     []string{"10.1.0.0/16"},
+		Destination: "0.0.0.0/0",
+		UDPOptions:  []UDPOptionConfig{{MinPort: 53, MaxPort: 53}},
+	}
+
+	ingress, egress := seclist.Rules()
+	if len(ingress) != 1 || len(egress) != 1 {
+		t.Fatalf("Expected 1 ingress and 1 egress rule, but got %d and %d", len(ingress), len(egress))
+	}
+	if got := ingress[0].SourceCIDRs(); len(got) != 2 {
+		t.Errorf("Expected 2 ingress sources, but got %v", got)
+	}
+	if got := egress[0].DestinationCIDRs(); len(got) != 1 || got[0] != "0.0.0.0/0" {
+		t.Errorf("Expected egress destination 0.0.0.0/0, but got %v", got)
+	}
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	pulumiconfig "github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
+)
+
+const (
+	// DefaultConfigDir is the directory holding one sub directory per environment
+	DefaultConfigDir = "config"
+	// ConfigFileName is the name of the config file inside an environment directory
+	ConfigFileName = "config.yaml"
+	// BaseConfigFileName is the optional shared config inside DefaultConfigDir that environments overlay
+	BaseConfigFileName = "base.yaml"
+	// EnvironmentConfigKey is the Pulumi config key used to override the environment
+	EnvironmentConfigKey = "environment"
+	// EnvironmentEnvVar is the environment variable used to override the environment
+	EnvironmentEnvVar = "INFRA_ENVIRONMENT"
+	// LenientConfigKey is the Pulumi config key that switches config loading to Lenient mode
+	LenientConfigKey = "lenientConfig"
+	// LenientEnvVar is the environment variable that switches config loading to Lenient mode
+	LenientEnvVar = "INFRA_LENIENT_CONFIG"
+)
+
+// ResolveEnvironment picks the environment name to load.
+// The environment variable wins over the Pulumi config key, which wins over the stack name.
+func ResolveEnvironment(stack, configOverride, envOverride string) string {
+	if env := strings.TrimSpace(envOverride); env != "" {
+		return env
+	}
+	if env := strings.TrimSpace(configOverride); env != "" {
+		return env
+	}
+	return strings.TrimSpace(stack)
+}
+
+// ConfigPath returns the config file for the given environment inside dir
+func ConfigPath(dir, environment string) (string, error) {
+	if environment == "" {
+		return "", fmt.Errorf("environment name is required")
+	}
+
+	path := filepath.Join(dir, environment, ConfigFileName)
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("no config found for environment %q at %s (available: %s)",
+				environment, path, strings.Join(AvailableEnvironments(dir), ", "))
+		}
+		return "", err
+	}
+
+	return path, nil
+}
+
+// AvailableEnvironments lists the environment directories in dir that contain a config file
+func AvailableEnvironments(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var envs []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, entry.Name(), ConfigFileName)); err == nil {
+			envs = append(envs, entry.Name())
+		}
+	}
+	sort.Strings(envs)
+
+	return envs
+}
+
+// ResolveDecodeMode returns Lenient when either override is a true boolean string, Strict otherwise
+func ResolveDecodeMode(configOverride, envOverride string) DecodeMode {
+	for _, v := range []string{envOverride, configOverride} {
+		if lenient, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil && lenient {
+			return Lenient
+		}
+	}
+	return Strict
+}
+
+// LayerPaths returns the files to load for an environment: the shared base file when present,
+// followed by the environment config
+func LayerPaths(dir, environment string) ([]string, error) {
+	path, err := ConfigPath(dir, environment)
+	if err != nil {
+		return nil, err
+	}
+
+	base := filepath.Join(dir, BaseConfigFileName)
+	if _, err := os.Stat(base); err != nil {
+		if os.IsNotExist(err) {
+			return []string{path}, nil
+		}
+		return nil, err
+	}
+
+	return []string{base, path}, nil
+}
+
+// LoadForStack loads the base config overlaid with the config matching the current Pulumi stack,
+// then the user data files it references
+func (c *Config) LoadForStack(ctx *pulumi.Context) error {
+	environment := ResolveEnvironment(
+		ctx.Stack(),
+		pulumiconfig.Get(ctx, EnvironmentConfigKey),
+		os.Getenv(EnvironmentEnvVar),
+	)
+
+	paths, err := LayerPaths(DefaultConfigDir, environment)
+	if err != nil {
+		return err
+	}
+
+	mode := ResolveDecodeMode(
+		pulumiconfig.Get(ctx, LenientConfigKey),
+		os.Getenv(LenientEnvVar),
+	)
+
+	if err := c.LoadLayeredMode(mode, paths...); err != nil {
+		return err
+	}
+	return c.LoadUserData(DefaultConfigDir)
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveEnvironment(t *testing.T) {
+	tests := []struct {
+		name           string
+		stack          string
+		configOverride string
+		envOverride    string
+		expected       string
+	}{
+		{"Stack name only", "prod", "", "", "prod"},
+		{"Config key overrides stack", "prod-eu", "prod", "", "prod"},
+		{"Env var overrides config key", "prod", "prod", "dev", "dev"},
+		{"Whitespace override ignored", "dev", "  ", "", "dev"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResolveEnvironment(tt.stack, tt.configOverride, tt.envOverride)
+			if got != tt.expected {
+				t.Errorf("Expected environment %s, but got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestConfigPath(t *testing.T) {
+	dir := t.TempDir()
+	for _, env := range []string{"dev", "prod"} {
+		if err := os.MkdirAll(filepath.Join(dir, env), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, env, ConfigFileName), []byte("---\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Directory without a config file should not count as an environment
+	if err := os.MkdirAll(filepath.Join(dir, "empty"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		environment   string
+		expectedError bool
+	}{
+		{"Existing dev environment", "dev", false},
+		{"Existing prod environment", "prod", false},
+		{"Missing environment", "staging", true},
+		{"Directory without config", "empty", true},
+		{"Empty environment", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := ConfigPath(dir, tt.environment)
+			if tt.expectedError && err == nil {
+				t.Errorf("Expected error but got path %s", path)
+			}
+			if !tt.expectedError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+
+	envs := AvailableEnvironments(dir)
+	if len(envs) != 2 || envs[0] != "dev" || envs[1] != "prod" {
+		t.Errorf("Expected environments [dev prod], but got %v", envs)
+	}
+}
@@ -0,0 +1,97 @@
+package config
+
+import "strings"
+
+// Bucket access types as expected by OCI
+const (
+	AccessNoPublic              = "NoPublicAccess"
+	AccessObjectRead            = "ObjectRead"
+	AccessObjectReadWithoutList = "ObjectReadWithoutList"
+)
+
+// Bucket storage tiers as expected by OCI. The tier of a bucket cannot be changed after creation.
+const (
+	StorageTierStandard = "Standard"
+	StorageTierArchive  = "Archive"
+)
+
+// Bucket versioning states as expected by OCI. An enabled bucket can only be suspended, not disabled.
+const (
+	VersioningEnabled   = "Enabled"
+	VersioningSuspended = "Suspended"
+	VersioningDisabled  = "Disabled"
+)
+
+// Lifecycle rule actions and targets as expected by OCI
+const (
+	LifecycleArchive          = "ARCHIVE"
+	LifecycleInfrequentAccess = "INFREQUENT_ACCESS"
+	LifecycleDelete           = "DELETE"
+	LifecycleAbort            = "ABORT"
+
+	LifecycleTargetObjects          = "objects"
+	LifecycleTargetPreviousVersions = "previous-object-versions"
+	LifecycleTargetMultipartUploads = "multipart-uploads"
+)
+
+// DurationConfig is an amount of days or years
+type DurationConfig struct {
+	Amount int    `yaml:"amount"`
+	Unit   string `yaml:"unit"`
+}
+
+// TimeUnit returns the unit as expected by OCI, DAYS or YEARS
+func (d DurationConfig) TimeUnit() string {
+	return strings.ToUpper(d.Unit)
+}
+
+// RetentionRuleConfig keeps objects from being modified or deleted for a duration, or indefinitely
+// when duration is omitted. A rule locked at time_rule_locked (RFC 3339) can no longer be removed.
+type RetentionRuleConfig struct {
+	Name           string          `yaml:"name"`
+	Duration       *DurationConfig `yaml:"duration,omitempty"`
+	TimeRuleLocked string          `yaml:"time_rule_locked,omitempty"`
+}
+
+// LifecycleRuleConfig archives, tiers or deletes objects, previous object versions or uncommitted
+// multipart uploads after a duration. Filters select objects by name.
+type LifecycleRuleConfig struct {
+	Name              string         `yaml:"name"`
+	Action            string         `yaml:"action"`
+	Target            string         `yaml:"target,omitempty"`
+	After             DurationConfig `yaml:"after"`
+	Disabled          bool           `yaml:"disabled,omitempty"`
+	InclusionPrefixes []string       `yaml:"inclusion_prefixes,omitempty"`
+	InclusionPatterns []string       `yaml:"inclusion_patterns,omitempty"`
+	ExclusionPatterns []string       `yaml:"exclusion_patterns,omitempty"`
+}
+
+// BucketConfig describes an object storage bucket. Unset access_type, storage_tier and versioning
+// default to NoPublicAccess, Standard and Disabled.
+type BucketConfig struct {
+	Name           string                `yaml:"name"`
+	AccessType     string                `yaml:"access_type,omitempty"`
+	StorageTier    string                `yaml:"storage_tier,omitempty"`
+	Versioning     string                `yaml:"versioning,omitempty"`
+	ObjectEvents   bool                  `yaml:"object_events,omitempty"`
+	KMSKeyID       string                `yaml:"kms_key_id,omitempty"`
+	RetentionRules []RetentionRuleConfig `yaml:"retention_rules,omitempty"`
+	LifecycleRules []LifecycleRuleConfig `yaml:"lifecycle_rules,omitempty"`
+}
+
+// StorageConfig describes the object storage buckets of the stack. Bucket names are unique in the
+// namespace of the tenancy, name_prefix lets every environment create its own copy of the buckets.
+type StorageConfig struct {
+	BaseConfig `yaml:",inline"`
+	Namespace  string         `yaml:"namespace,omitempty"`
+	NamePrefix string         `yaml:"name_prefix,omitempty"`
+	Buckets    []BucketConfig `yaml:"buckets,omitempty"`
+}
+
+// BucketName returns the name of a bucket in OCI, prefixed with name_prefix when set
+func (s StorageConfig) BucketName(bucket BucketConfig) string {
+	if s.NamePrefix == "" {
+		return bucket.Name
+	}
+	return s.NamePrefix + "-" + bucket.Name
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// cloudConfigHeader is the first line cloud-init requires to treat user data as cloud-config
+const cloudConfigHeader = "#cloud-config"
+
+// UserDataConfig is the cloud-init user data of an instance, given inline or in a file relative to the
+// config directory. A template looks up stack outputs with {{ output "heatwave.host" }}, see Render.
+type UserDataConfig struct {
+	Inline   string `yaml:"inline,omitempty"`
+	File     string `yaml:"file,omitempty"`
+	Template bool   `yaml:"template,omitempty"`
+
+	// fileContent is the content of File read by LoadUserData
+	fileContent string
+}
+
+// Content returns the inline user data or the content of the file read by LoadUserData
+func (u UserDataConfig) Content() string {
+	if u.Inline != "" {
+		return u.Inline
+	}
+	return u.fileContent
+}
+
+// Render returns the user data, rendering templates with outputs, the stack outputs keyed by export key.
+// The output function of a template walks outputs along a dotted path such as buckets.logs.name.
+func (u UserDataConfig) Render(outputs map[string]interface{}) (string, error) {
+	return u.render(func(path string) (string, error) {
+		return lookupOutput(outputs, path)
+	})
+}
+
+// render renders the user data with the given output function, or returns it as is when it is no template
+func (u UserDataConfig) render(output func(string) (string, error)) (string, error) {
+	if !u.Template {
+		return u.Content(), nil
+	}
+
+	tmpl, err := template.New("user_data").Funcs(template.FuncMap{"output": output}).Option("missingkey=error").Parse(u.Content())
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, nil); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// lookupOutput walks the nested output maps along a dotted path
+func lookupOutput(outputs map[string]interface{}, path string) (string, error) {
+	var value interface{} = outputs
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("output %s not found", path)
+		}
+		if value, ok = m[key]; !ok {
+			return "", fmt.Errorf("output %s not found", path)
+		}
+	}
+	if value == nil {
+		return "", nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// LoadUserData reads the user data files of the compute instances relative to dir
+func (c *Config) LoadUserData(dir string) error {
+	for i, instance := range c.Compute.Instances {
+		if instance.UserData == nil || instance.UserData.File == "" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, instance.UserData.File))
+		if err != nil {
+			return fmt.Errorf("instance %s user_data: %w", instance.Name, err)
+		}
+		c.Compute.Instances[i].UserData.fileContent = string(data)
+	}
+	return nil
+}
+
+// validate checks that the user data is set once and renders, with placeholder outputs for templates,
+// to valid YAML when it is cloud-config
+func (u UserDataConfig) validate(v *validator, path string) {
+	switch {
+	case u.Inline != "" && u.File != "":
+		v.addf(path, "only one of inline or file may be set")
+		return
+	case u.Inline == "" && u.File == "":
+		v.addf(path, "inline or file is required")
+		return
+	case u.Content() == "":
+		v.addf(path+".file", "%q is empty or was not loaded", u.File)
+		return
+	}
+
+	rendered, err := u.render(func(string) (string, error) { return "placeholder", nil })
+	if err != nil {
+		v.addf(path, "invalid template: %v", err)
+		return
+	}
+	if !strings.HasPrefix(rendered, cloudConfigHeader) {
+		return
+	}
+	var cloudConfig map[string]interface{}
+	if err := yaml.Unmarshal([]byte(rendered), &cloudConfig); err != nil {
+		v.addf(path, "invalid cloud-config: %v", err)
+	}
+}
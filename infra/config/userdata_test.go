@@ -0,0 +1,90 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUserDataRender(t *testing.T) {
+	outputs := map[string]interface{}{
+		"heatwave": map[string]interface{}{"host": "10.0.3.10", "port": 3306},
+		"buckets":  map[string]interface{}{"logs": map[string]interface{}{"name": "dev-logs"}},
+	}
+
+	tests := []struct {
+		name          string
+		userData      UserDataConfig
+		expected      string
+		expectedError bool
+	}{
+		{
+			name:     "Not a template",
+			userData: UserDataConfig{Inline: "#!/bin/sh\necho {{ output \"heatwave.host\" }}\n"},
+			expected: "#!/bin/sh\necho {{ output \"heatwave.host\" }}\n",
+		},
+		{
+			name:     "Outputs",
+			userData: UserDataConfig{Inline: `DB={{ output "heatwave.host" }}:{{ output "heatwave.port" }} LOGS={{ output "buckets.logs.name" }}`, Template: true},
+			expected: "DB=10.0.3.10:3306 LOGS=dev-logs",
+		},
+		{
+			name:          "Unknown output",
+			userData:      UserDataConfig{Inline: `{{ output "buckets.artifacts.name" }}`, Template: true},
+			expectedError: true,
+		},
+		{
+			name:          "Path through a value",
+			userData:      UserDataConfig{Inline: `{{ output "heatwave.host.name" }}`, Template: true},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := tt.userData.Render(outputs)
+			if tt.expectedError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if rendered != tt.expected {
+				t.Errorf("Expected %q, but got %q", tt.expected, rendered)
+			}
+		})
+	}
+}
+
+func TestLoadUserData(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "cloud-init"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cloud-init", "app.yaml"), []byte("#cloud-config\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Config{Compute: ComputeConfig{Instances: []InstanceConfig{
+		{Name: "file", UserData: &UserDataConfig{File: "cloud-init/app.yaml"}},
+		{Name: "inline", UserData: &UserDataConfig{Inline: "#!/bin/sh\n"}},
+		{Name: "none"},
+	}}}
+	if err := cfg.LoadUserData(dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := cfg.Compute.Instances[0].UserData.Content(); got != "#cloud-config\n" {
+		t.Errorf("Expected the file content, but got %q", got)
+	}
+	if got := cfg.Compute.Instances[1].UserData.Content(); got != "#!/bin/sh\n" {
+		t.Errorf("Expected the inline content, but got %q", got)
+	}
+
+	cfg.Compute.Instances[0].UserData.File = "cloud-init/missing.yaml"
+	if err := cfg.LoadUserData(dir); err == nil {
+		t.Errorf("Expected error but got none")
+	}
+}
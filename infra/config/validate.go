@@ -0,0 +1,1028 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationError describes a single problem found in the config, located by its YAML path
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// ValidationErrors collects every problem found by Validate
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("invalid config (%d errors):\n  %s", len(e), strings.Join(msgs, "\n  "))
+}
+
+// validator accumulates errors while walking the config
+type validator struct {
+	errs ValidationErrors
+}
+
+func (v *validator) addf(path, format string, args ...interface{}) {
+	v.errs = append(v.errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// Validate checks the whole config and returns every problem found as ValidationErrors
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	compartments := c.Identity.CompartmentNames()
+
+	c.Identity.validate(v, "identity")
+	c.Network.validate(v, "network", compartments)
+	c.Compute.validate(v, "compute", c.Network, compartments)
+	c.Bastion.validate(v, "bastion", c.Network, c.Compute, compartments)
+	c.Heatwave.validate(v, "heatwave", c.Network, compartments)
+	c.Storage.validate(v, "storage", compartments)
+
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func (b BaseConfig) validate(v *validator, path string, required bool, compartments map[string]bool) {
+	hasCompartment := b.CompartmentID != "" || b.Compartment != ""
+	if required && !hasCompartment {
+		v.addf(path+".compartment_id", "compartment_id or compartment is required")
+	}
+	if b.Region != "" && !hasCompartment {
+		v.addf(path+".compartment_id", "compartment_id or compartment is required when region is set")
+	}
+	if b.CompartmentID != "" && b.Compartment != "" {
+		v.addf(path+".compartment", "only one of compartment_id or compartment may be set")
+	}
+	if b.Compartment != "" && !compartments[b.Compartment] {
+		v.addf(path+".compartment", "compartment %q is not defined in identity.compartments", b.Compartment)
+	}
+}
+
+func (i IdentityConfig) validate(v *validator, path string) {
+	if i.TenancyID == "" && (len(i.Compartments) > 0 || len(i.Groups) > 0 || len(i.DynamicGroups) > 0 || len(i.Policies) > 0) {
+		v.addf(path+".tenancy_id", "is required when compartments, groups, dynamic groups or policies are defined")
+	}
+
+	names := make(map[string]bool)
+	var walk func(compartments []CompartmentConfig, path string)
+	walk = func(compartments []CompartmentConfig, path string) {
+		for j, compartment := range compartments {
+			p := fmt.Sprintf("%s.compartments[%d]", path, j)
+			checkName(v, p+".name", compartment.Name, names)
+			if compartment.Description == "" {
+				v.addf(p+".description", "is required")
+			}
+			walk(compartment.Compartments, p)
+		}
+	}
+	walk(i.Compartments, path)
+
+	groupNames := make(map[string]bool)
+	for j, group := range i.Groups {
+		p := fmt.Sprintf("%s.groups[%d]", path, j)
+		checkName(v, p+".name", group.Name, groupNames)
+		if group.Description == "" {
+			v.addf(p+".description", "is required")
+		}
+	}
+
+	dynamicGroupNames := make(map[string]bool)
+	for j, group := range i.DynamicGroups {
+		p := fmt.Sprintf("%s.dynamic_groups[%d]", path, j)
+		checkName(v, p+".name", group.Name, dynamicGroupNames)
+		if group.Description == "" {
+			v.addf(p+".description", "is required")
+		}
+		i.validateDynamicGroupMatch(v, p, group, names)
+	}
+
+	policyNames := make(map[string]bool)
+	for j, policy := range i.Policies {
+		p := fmt.Sprintf("%s.policies[%d]", path, j)
+		checkName(v, p+".name", policy.Name, policyNames)
+		if policy.Description == "" {
+			v.addf(p+".description", "is required")
+		}
+		if policy.Compartment != "" && !names[policy.Compartment] {
+			v.addf(p+".compartment", "compartment %q is not defined in identity.compartments", policy.Compartment)
+			continue
+		}
+		if len(policy.Statements) == 0 {
+			v.addf(p+".statements", "at least one statement is required")
+		}
+		for k, statement := range policy.Statements {
+			rendered, err := i.PolicyStatements(PolicyConfig{Name: policy.Name, Compartment: policy.Compartment, Statements: []string{statement}})
+			if err != nil {
+				v.addf(fmt.Sprintf("%s.statements[%d]", p, k), "%v", err)
+				continue
+			}
+			if err := ValidatePolicyStatement(rendered[0]); err != nil {
+				v.addf(fmt.Sprintf("%s.statements[%d]", p, k), "%v", err)
+			}
+		}
+	}
+}
+
+// validateDynamicGroupMatch checks that a dynamic group has exactly one of matching_rule or match,
+// and that a generated rule references known compartments and complete tags
+func (i IdentityConfig) validateDynamicGroupMatch(v *validator, path string, group DynamicGroupConfig, compartments map[string]bool) {
+	switch {
+	case group.MatchingRule == "" && group.Match == nil:
+		v.addf(path, "matching_rule or match is required")
+		return
+	case group.MatchingRule != "" && group.Match != nil:
+		v.addf(path, "only one of matching_rule or match may be set")
+		return
+	case group.Match == nil:
+		return
+	}
+
+	match := group.Match
+	for k, name := range match.Compartments {
+		if !compartments[name] {
+			v.addf(fmt.Sprintf("%s.match.compartments[%d]", path, k), "compartment %q is not defined in identity.compartments", name)
+		}
+	}
+	for k, tag := range match.Tags {
+		p := fmt.Sprintf("%s.match.tags[%d]", path, k)
+		if tag.Namespace == "" {
+			v.addf(p+".namespace", "is required")
+		}
+		if tag.Key == "" {
+			v.addf(p+".key", "is required")
+		}
+	}
+
+	matchedCompartments := len(match.Compartments) + len(match.CompartmentIDs)
+	if matchedCompartments+len(match.Tags) == 0 {
+		v.addf(path+".match", "at least one compartment, compartment id or tag is required")
+	}
+	if match.All && matchedCompartments > 1 {
+		v.addf(path+".match", "an instance is in a single compartment, all cannot match more than one compartment")
+	}
+}
+
+func (n NetworkConfig) validate(v *validator, path string, compartments map[string]bool) {
+	n.BaseConfig.validate(v, path, true, compartments)
+
+	if n.DisplayName == "" {
+		v.addf(path+".display_name", "is required")
+	}
+
+	vcn, vcnOK := parseCIDR(v, path+".cidr_block", n.CidrBlock)
+	if n.DNSLabel != "" {
+		validateDNSLabel(v, path+".dns_label", n.DNSLabel)
+	}
+
+	dhcpOptions := make(map[string]bool)
+	for i, opts := range n.DHCPOptions {
+		p := fmt.Sprintf("%s.dhcp_options[%d]", path, i)
+		checkName(v, p+".name", opts.Name, dhcpOptions)
+
+		switch opts.ServerType {
+		case "", "VcnLocalPlusInternet":
+			if len(opts.CustomDNSServers) > 0 {
+				v.addf(p+".custom_dns_servers", "requires server_type CustomDnsServer")
+			}
+		case "CustomDnsServer":
+			if len(opts.CustomDNSServers) == 0 || len(opts.CustomDNSServers) > 3 {
+				v.addf(p+".custom_dns_servers", "must list between 1 and 3 DNS servers")
+			}
+		default:
+			v.addf(p+".server_type", "%q must be VcnLocalPlusInternet or CustomDnsServer", opts.ServerType)
+		}
+
+		for j, server := range opts.CustomDNSServers {
+			if _, err := netip.ParseAddr(server); err != nil {
+				v.addf(fmt.Sprintf("%s.custom_dns_servers[%d]", p, j), "%q is not a valid IP address", server)
+			}
+		}
+	}
+
+	subnetNames := make(map[string]bool)
+	subnetDNSLabels := make(map[string]bool)
+	var subnetPrefixes []netip.Prefix
+	var subnetPaths []string
+	for i, subnet := range n.Subnets {
+		p := fmt.Sprintf("%s.subnets[%d]", path, i)
+		checkName(v, p+".name", subnet.Name, subnetNames)
+
+		if subnet.DNSLabel != "" {
+			validateDNSLabel(v, p+".dns_label", subnet.DNSLabel)
+			if n.DNSLabel == "" {
+				v.addf(p+".dns_label", "requires %s.dns_label to be set", path)
+			}
+			checkName(v, p+".dns_label", subnet.DNSLabel, subnetDNSLabels)
+		}
+		if subnet.DHCPOptions != "" && !dhcpOptions[subnet.DHCPOptions] {
+			v.addf(p+".dhcp_options", "dhcp options %q are not defined in %s.dhcp_options", subnet.DHCPOptions, path)
+		}
+
+		prefix, ok := parseCIDR(v, p+".cidr_block", subnet.CidrBlock)
+		if !ok {
+			continue
+		}
+		if vcnOK && !containsPrefix(vcn, prefix) {
+			v.addf(p+".cidr_block", "%s is not inside the VCN cidr_block %s", prefix, vcn)
+		}
+		for j, other := range subnetPrefixes {
+			if prefix.Overlaps(other) {
+				v.addf(p+".cidr_block", "%s overlaps %s (%s)", prefix, other, subnetPaths[j])
+			}
+		}
+		subnetPrefixes = append(subnetPrefixes, prefix)
+		subnetPaths = append(subnetPaths, p)
+	}
+
+	seclistNames := make(map[string]bool)
+	seclistNameCounts := make(map[string]int)
+	for i, seclist := range n.SecurityLists {
+		p := fmt.Sprintf("%s.security_lists[%d]", path, i)
+		checkName(v, p+".display_name", seclist.DisplayName, seclistNames)
+		seclistNameCounts[seclist.DisplayName]++
+
+		if seclist.SubnetName != "" && !subnetNames[seclist.SubnetName] {
+			v.addf(p+".subnet_name", "subnet %q is not defined in %s.subnets", seclist.SubnetName, path)
+		}
+
+		if !seclist.IsFlat() {
+			for j, rule := range seclist.IngressRules {
+				validateSecurityRule(v, fmt.Sprintf("%s.ingress_rules[%d]", p, j), "source", rule.Source, rule.Sources, rule)
+			}
+			for j, rule := range seclist.EgressRules {
+				validateSecurityRule(v, fmt.Sprintf("%s.egress_rules[%d]", p, j), "destination", rule.Destination, rule.Destinations, rule)
+			}
+			continue
+		}
+		if len(seclist.IngressRules) > 0 || len(seclist.EgressRules) > 0 {
+			v.addf(p, "protocol, source and destination cannot be combined with ingress_rules or egress_rules")
+			continue
+		}
+
+		validateRuleOptions(v, p, seclist.Protocol, seclist.TCPOptions, seclist.UDPOptions, seclist.ICMPOptions)
+
+		if len(seclist.SourceCIDRs()) == 0 && len(seclist.DestinationCIDRs()) == 0 {
+			v.addf(p, "either source or destination is required")
+		}
+		validateEndpoints(v, p, "source", seclist.Source, seclist.Sources)
+		validateEndpoints(v, p, "destination", seclist.Destination, seclist.Destinations)
+	}
+
+	groupedNames := make(map[string]bool)
+	subnetSeclists := make(map[string]int)
+	for _, seclist := range n.GroupedSecurityLists() {
+		// duplicates among the configured names are already reported above
+		if groupedNames[seclist.DisplayName] && seclistNameCounts[seclist.DisplayName] < 2 {
+			v.addf(path+".security_lists", "merged security list %q collides with another security list", seclist.DisplayName)
+		}
+		groupedNames[seclist.DisplayName] = true
+		if seclist.SubnetName != "" {
+			subnetSeclists[seclist.SubnetName]++
+		}
+	}
+	for _, subnet := range n.Subnets {
+		if count := subnetSeclists[subnet.Name]; count > MaxSecurityListsPerSubnet {
+			v.addf(path+".security_lists", "subnet %q has %d security lists, OCI allows at most %d", subnet.Name, count, MaxSecurityListsPerSubnet)
+		}
+	}
+
+	gatewayNames := make(map[string]bool)
+	gatewayTypes := make(map[string]string)
+	seenGatewayTypes := make(map[string]bool)
+	for i, gateway := range n.Gateways {
+		p := fmt.Sprintf("%s.gateways[%d]", path, i)
+		checkName(v, p+".name", gateway.Name, gatewayNames)
+		gatewayTypes[gateway.Name] = gateway.Type
+
+		switch gateway.Type {
+		case "internet", "nat", "service":
+			if seenGatewayTypes[gateway.Type] {
+				v.addf(p+".type", "only one %s gateway is allowed per VCN", gateway.Type)
+			}
+			seenGatewayTypes[gateway.Type] = true
+		default:
+			v.addf(p+".type", "%q must be internet, nat or service", gateway.Type)
+		}
+
+		switch {
+		case gateway.Services == "":
+		case gateway.Type != "service":
+			v.addf(p+".services", "is only valid for service gateways")
+		case gateway.Services != "all" && gateway.Services != "objectstorage":
+			v.addf(p+".services", "%q must be all or objectstorage", gateway.Services)
+		}
+	}
+
+	routeTables := make(map[string]bool)
+	for i, rt := range n.RouteTables {
+		p := fmt.Sprintf("%s.route_tables[%d]", path, i)
+		checkName(v, p+".name", rt.Name, routeTables)
+
+		for j, rule := range rt.Rules {
+			rp := fmt.Sprintf("%s.rules[%d]", p, j)
+			gatewayType, ok := gatewayTypes[rule.Gateway]
+			if !ok {
+				v.addf(rp+".gateway", "gateway %q is not defined in %s.gateways", rule.Gateway, path)
+				continue
+			}
+			if gatewayType != "service" {
+				parseCIDR(v, rp+".destination", rule.Destination)
+			}
+		}
+	}
+
+	for i, subnet := range n.Subnets {
+		if subnet.RouteTable != "" && !routeTables[subnet.RouteTable] {
+			v.addf(fmt.Sprintf("%s.subnets[%d].route_table", path, i), "route table %q is not defined in %s.route_tables", subnet.RouteTable, path)
+		}
+	}
+
+	nsgNames := n.nsgNames()
+	seenNSGs := make(map[string]bool)
+	for i, nsg := range n.NetworkSecurityGroups {
+		p := fmt.Sprintf("%s.network_security_groups[%d]", path, i)
+		checkName(v, p+".display_name", nsg.DisplayName, seenNSGs)
+
+		for j, rule := range nsg.Rules {
+			rp := fmt.Sprintf("%s.rules[%d]", p, j)
+
+			validateRuleOptions(v, rp, rule.Protocol, rule.TCPOptions, rule.UDPOptions, rule.ICMPOptions)
+
+			switch strings.ToLower(rule.Direction) {
+			case "ingress":
+				validateNSGEndpoint(v, rp+".source", rule.Source, nsgNames)
+			case "egress":
+				validateNSGEndpoint(v, rp+".destination", rule.Destination, nsgNames)
+			default:
+				v.addf(rp+".direction", "%q must be ingress or egress", rule.Direction)
+			}
+		}
+	}
+}
+
+// validateNSGEndpoint checks that a network security group rule endpoint is a CIDR block or a known group
+func validateNSGEndpoint(v *validator, path, value string, nsgs map[string]bool) {
+	if !isSet(value) {
+		v.addf(path, "is required")
+		return
+	}
+	if nsgs[value] {
+		return
+	}
+	if _, err := netip.ParsePrefix(value); err != nil {
+		v.addf(path, "%q is neither a CIDR block nor a network security group", value)
+	}
+}
+
+func (c ComputeConfig) validate(v *validator, path string, network NetworkConfig, compartments map[string]bool) {
+	c.BaseConfig.validate(v, path, len(c.Instances) > 0 || len(c.Pools) > 0, compartments)
+
+	subnets := network.subnetNames()
+	publicSubnets := make(map[string]bool)
+	for _, subnet := range network.Subnets {
+		publicSubnets[subnet.Name] = subnet.Public
+	}
+	nsgs := network.nsgNames()
+
+	names := make(map[string]bool)
+	for i, instance := range c.Instances {
+		p := fmt.Sprintf("%s.instances[%d]", path, i)
+		checkName(v, p+".name", instance.Name, names)
+		instance.validate(v, p, subnets, publicSubnets, nsgs)
+	}
+
+	pools := make(map[string]bool)
+	for i, pool := range c.Pools {
+		p := fmt.Sprintf("%s.pools[%d]", path, i)
+		checkName(v, p+".name", pool.Name, pools)
+		if pool.Size < 0 {
+			v.addf(p+".size", "must be 0 or greater")
+		}
+
+		template := p + ".template"
+		pool.Template.validate(v, template, subnets, publicSubnets, nsgs)
+		if pool.Template.AvailabilityDomain != "" || pool.Template.FaultDomain != "" {
+			v.addf(template, "availability_domain and fault_domain are set by availability_domains and fault_domains of the pool")
+		}
+		if len(pool.Template.Volumes) > 0 {
+			v.addf(template+".volumes", "data volumes are not supported in pool templates")
+		}
+
+		for j, ad := range pool.AvailabilityDomains {
+			if n, err := strconv.Atoi(ad); ad == "" || err == nil && n < 1 {
+				v.addf(fmt.Sprintf("%s.availability_domains[%d]", p, j), "%q must be an index of 1 or greater or a name", ad)
+			}
+		}
+		for j, fd := range pool.FaultDomains {
+			if !validFaultDomain(fd) {
+				v.addf(fmt.Sprintf("%s.fault_domains[%d]", p, j), "%q must be an index between 1 and %d or FAULT-DOMAIN-1 to FAULT-DOMAIN-%d", fd, FaultDomainsPerAD, FaultDomainsPerAD)
+			}
+		}
+	}
+
+	switch c.Spread {
+	case "", SpreadNone, SpreadFaultDomains, SpreadAvailabilityDomains:
+	default:
+		v.addf(path+".spread", "%q must be %s, %s or %s", c.Spread, SpreadNone, SpreadFaultDomains, SpreadAvailabilityDomains)
+	}
+}
+
+// validate checks an instance, or the template of an instance pool, against the subnets and network
+// security groups of the network config
+func (instance InstanceConfig) validate(v *validator, p string, subnets, publicSubnets, nsgs map[string]bool) {
+	if instance.Shape == "" {
+		v.addf(p+".shape", "is required")
+	}
+	switch {
+	case instance.Subnet == "" && instance.SubnetID == "":
+		v.addf(p+".subnet", "subnet or subnet_id is required")
+	case instance.Subnet != "" && instance.SubnetID != "":
+		v.addf(p+".subnet", "only one of subnet or subnet_id may be set")
+	case instance.Subnet != "" && !subnets[instance.Subnet]:
+		v.addf(p+".subnet", "subnet %q is not defined in network.subnets", instance.Subnet)
+	case instance.Subnet != "" && instance.AssignPublicIP != nil && *instance.AssignPublicIP && !publicSubnets[instance.Subnet]:
+		v.addf(p+".assign_public_ip", "subnet %q is private and prohibits public IPs", instance.Subnet)
+	}
+	if instance.ImageOCID == "" && instance.Image == nil {
+		v.addf(p+".image_ocid", "image_ocid or image is required")
+	}
+	if instance.Image != nil {
+		instance.Image.validate(v, p+".image")
+	}
+	if instance.UserData != nil {
+		instance.UserData.validate(v, p+".user_data")
+	}
+	if size := instance.BootVolumeSizeGB; size != 0 && (size < MinVolumeSizeGB || size > MaxVolumeSizeGB) {
+		v.addf(p+".boot_volume_size_gb", "%d must be between %d and %d", size, MinVolumeSizeGB, MaxVolumeSizeGB)
+	}
+	if vpus := instance.BootVolumeVPUs; vpus != nil {
+		validateVPUs(v, p+".boot_volume_vpus_per_gb", *vpus, MinBootVolumeVPUs)
+	}
+	volumes := make(map[string]bool)
+	devices := make(map[string]bool)
+	for j, volume := range instance.Volumes {
+		volume.validate(v, fmt.Sprintf("%s.volumes[%d]", p, j), volumes, devices)
+	}
+	if instance.SSHPublicKey == "" {
+		v.addf(p+".ssh_public_key", "is required")
+	}
+	for j, nsg := range instance.NSGs {
+		if !nsgs[nsg] {
+			v.addf(fmt.Sprintf("%s.nsgs[%d]", p, j), "network security group %q is not defined in network.network_security_groups", nsg)
+		}
+	}
+	if instance.OCPUCount != nil && *instance.OCPUCount <= 0 {
+		v.addf(p+".ocpu_count", "must be greater than 0")
+	}
+	if instance.MemoryGB != nil && *instance.MemoryGB <= 0 {
+		v.addf(p+".memory_gb", "must be greater than 0")
+	}
+	if n, err := strconv.Atoi(instance.AvailabilityDomain); err == nil && n < 1 {
+		v.addf(p+".availability_domain", "index %d must be 1 or greater", n)
+	}
+	if fd := instance.FaultDomain; fd != "" && !validFaultDomain(fd) {
+		v.addf(p+".fault_domain", "%q must be an index between 1 and %d or FAULT-DOMAIN-1 to FAULT-DOMAIN-%d", fd, FaultDomainsPerAD, FaultDomainsPerAD)
+	}
+}
+
+// validate checks a data volume, names and devices must be unique among the volumes of an instance
+func (c BlockVolumeConfig) validate(v *validator, path string, names, devices map[string]bool) {
+	checkName(v, path+".name", c.Name, names)
+	if c.SizeGB < MinVolumeSizeGB || c.SizeGB > MaxVolumeSizeGB {
+		v.addf(path+".size_gb", "%d must be between %d and %d", c.SizeGB, MinVolumeSizeGB, MaxVolumeSizeGB)
+	}
+	if c.VPUs != nil {
+		validateVPUs(v, path+".vpus_per_gb", *c.VPUs, 0)
+	}
+	switch c.Attachment {
+	case "", AttachmentISCSI, AttachmentParavirtualized:
+	default:
+		v.addf(path+".attachment", "%q must be %s or %s", c.Attachment, AttachmentISCSI, AttachmentParavirtualized)
+	}
+	if c.Device != "" {
+		suffix := strings.TrimPrefix(c.Device, VolumeDevicePrefix)
+		switch {
+		case suffix == c.Device || suffix == "" || strings.Trim(suffix, "abcdefghijklmnopqrstuvwxyz") != "":
+			v.addf(path+".device", "%q must be a %s<letters> device path", c.Device, VolumeDevicePrefix)
+		case suffix == "a":
+			v.addf(path+".device", "%q is the boot volume", c.Device)
+		case devices[c.Device]:
+			v.addf(path+".device", "%q is used by another volume", c.Device)
+		}
+		devices[c.Device] = true
+	}
+	switch {
+	case c.BackupPolicy == "", c.BackupPolicy == BackupPolicyGold, c.BackupPolicy == BackupPolicySilver,
+		c.BackupPolicy == BackupPolicyBronze, strings.HasPrefix(c.BackupPolicy, "ocid1.volumebackuppolicy."):
+	default:
+		v.addf(path+".backup_policy", "%q must be %s, %s, %s or a volume backup policy OCID", c.BackupPolicy, BackupPolicyGold, BackupPolicySilver, BackupPolicyBronze)
+	}
+}
+
+// validateVPUs checks a volume performance level in VPUs per GB
+func validateVPUs(v *validator, path string, vpus, min int) {
+	if vpus < min || vpus > MaxVolumeVPUs || vpus%VolumeVPUsStep != 0 {
+		v.addf(path, "%d must be a multiple of %d between %d and %d", vpus, VolumeVPUsStep, min, MaxVolumeVPUs)
+	}
+}
+
+// validate checks the image selector of an instance
+func (c ImageConfig) validate(v *validator, path string) {
+	if c.OperatingSystem == "" {
+		v.addf(path+".operating_system", "is required")
+	}
+	if c.DisplayName != "" {
+		if _, err := regexp.Compile(c.DisplayName); err != nil {
+			v.addf(path+".display_name", "invalid regular expression: %v", err)
+		}
+	}
+	if c.Date != "" {
+		if _, err := time.Parse(ImageDateLayout, c.Date); err != nil {
+			v.addf(path+".date", "%q must be a YYYY-MM-DD date", c.Date)
+		}
+	}
+}
+
+// validFaultDomain reports whether fd is a fault domain index or FAULT-DOMAIN-n name
+func validFaultDomain(fd string) bool {
+	n, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(fd), "FAULT-DOMAIN-"))
+	return err == nil && n >= 1 && n <= FaultDomainsPerAD
+}
+
+func (s StorageConfig) validate(v *validator, path string, compartments map[string]bool) {
+	s.BaseConfig.validate(v, path, len(s.Buckets) > 0, compartments)
+
+	names := make(map[string]bool)
+	for i, bucket := range s.Buckets {
+		p := fmt.Sprintf("%s.buckets[%d]", path, i)
+		checkName(v, p+".name", bucket.Name, names)
+		if name := s.BucketName(bucket); bucket.Name != "" && !validBucketName(name) {
+			v.addf(p+".name", "bucket name %q must be at most 256 letters, numbers, dashes, underscores and periods", name)
+		}
+
+		switch bucket.AccessType {
+		case "", AccessNoPublic, AccessObjectRead, AccessObjectReadWithoutList:
+		default:
+			v.addf(p+".access_type", "%q must be %s, %s or %s", bucket.AccessType, AccessNoPublic, AccessObjectRead, AccessObjectReadWithoutList)
+		}
+		switch bucket.StorageTier {
+		case "", StorageTierStandard, StorageTierArchive:
+		default:
+			v.addf(p+".storage_tier", "%q must be %s or %s", bucket.StorageTier, StorageTierStandard, StorageTierArchive)
+		}
+		switch bucket.Versioning {
+		case "", VersioningEnabled, VersioningSuspended, VersioningDisabled:
+		default:
+			v.addf(p+".versioning", "%q must be %s, %s or %s", bucket.Versioning, VersioningEnabled, VersioningSuspended, VersioningDisabled)
+		}
+		if bucket.KMSKeyID != "" && !strings.HasPrefix(bucket.KMSKeyID, "ocid1.key.") {
+			v.addf(p+".kms_key_id", "%q is not a vault key OCID", bucket.KMSKeyID)
+		}
+
+		if len(bucket.RetentionRules) > 0 && bucket.Versioning == VersioningEnabled {
+			v.addf(p+".retention_rules", "retention rules cannot be added to a bucket with versioning enabled")
+		}
+		ruleNames := make(map[string]bool)
+		for j, rule := range bucket.RetentionRules {
+			rp := fmt.Sprintf("%s.retention_rules[%d]", p, j)
+			checkName(v, rp+".name", rule.Name, ruleNames)
+			if rule.Duration != nil {
+				validateDuration(v, rp+".duration", *rule.Duration)
+			}
+			if rule.TimeRuleLocked != "" {
+				if _, err := time.Parse(time.RFC3339, rule.TimeRuleLocked); err != nil {
+					v.addf(rp+".time_rule_locked", "%q is not an RFC 3339 time", rule.TimeRuleLocked)
+				}
+				if rule.Duration == nil {
+					v.addf(rp+".time_rule_locked", "an indefinite retention rule cannot be locked")
+				}
+			}
+		}
+
+		ruleNames = make(map[string]bool)
+		for j, rule := range bucket.LifecycleRules {
+			rp := fmt.Sprintf("%s.lifecycle_rules[%d]", p, j)
+			checkName(v, rp+".name", rule.Name, ruleNames)
+			validateDuration(v, rp+".after", rule.After)
+
+			target := rule.Target
+			switch target {
+			case "":
+				target = LifecycleTargetObjects
+			case LifecycleTargetObjects, LifecycleTargetPreviousVersions, LifecycleTargetMultipartUploads:
+			default:
+				v.addf(rp+".target", "%q must be %s, %s or %s", rule.Target, LifecycleTargetObjects, LifecycleTargetPreviousVersions, LifecycleTargetMultipartUploads)
+				continue
+			}
+
+			switch rule.Action {
+			case LifecycleArchive, LifecycleInfrequentAccess, LifecycleDelete:
+				if target == LifecycleTargetMultipartUploads {
+					v.addf(rp+".action", "multipart uploads can only be aborted with %s", LifecycleAbort)
+				}
+			case LifecycleAbort:
+				if target != LifecycleTargetMultipartUploads {
+					v.addf(rp+".action", "%s only applies to %s", LifecycleAbort, LifecycleTargetMultipartUploads)
+				}
+			default:
+				v.addf(rp+".action", "%q must be %s, %s, %s or %s", rule.Action, LifecycleArchive, LifecycleInfrequentAccess, LifecycleDelete, LifecycleAbort)
+			}
+			if rule.Action == LifecycleArchive && bucket.StorageTier == StorageTierArchive {
+				v.addf(rp+".action", "objects of an archive bucket are already archived")
+			}
+		}
+	}
+}
+
+// validateDuration checks that a duration is a positive amount of days or years
+func validateDuration(v *validator, path string, d DurationConfig) {
+	if d.Amount <= 0 {
+		v.addf(path+".amount", "must be greater than 0")
+	}
+	if unit := d.TimeUnit(); unit != "DAYS" && unit != "YEARS" {
+		v.addf(path+".unit", "%q must be days or years", d.Unit)
+	}
+}
+
+// validBucketName reports whether name is a valid object storage bucket name
+func validBucketName(name string) bool {
+	if len(name) == 0 || len(name) > 256 {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (b BastionConfig) validate(v *validator, path string, network NetworkConfig, compute ComputeConfig, compartments map[string]bool) {
+	b.BaseConfig.validate(v, path, b.Enabled(), compartments)
+
+	if !b.Enabled() {
+		if b.Name != "" || len(b.ClientCIDRs) > 0 || b.MaxSessionTTLSeconds != 0 || len(b.Sessions) > 0 {
+			v.addf(path+".subnet", "is required to create a bastion")
+		}
+		return
+	}
+
+	if !network.subnetNames()[b.Subnet] {
+		v.addf(path+".subnet", "subnet %q is not defined in network.subnets", b.Subnet)
+	}
+	if b.Name == "" {
+		v.addf(path+".name", "is required")
+	} else if !alphanumeric(b.Name) {
+		v.addf(path+".name", "%q must only contain letters and numbers", b.Name)
+	}
+	if len(b.ClientCIDRs) == 0 {
+		v.addf(path+".client_cidrs", "at least one client CIDR block is required")
+	}
+	for i, cidr := range b.ClientCIDRs {
+		parseCIDR(v, fmt.Sprintf("%s.client_cidrs[%d]", path, i), cidr)
+	}
+
+	maxTTL := MaxSessionTTLSeconds
+	if b.MaxSessionTTLSeconds != 0 {
+		if b.MaxSessionTTLSeconds < MinSessionTTLSeconds || b.MaxSessionTTLSeconds > MaxSessionTTLSeconds {
+			v.addf(path+".max_session_ttl_seconds", "must be between %d and %d", MinSessionTTLSeconds, MaxSessionTTLSeconds)
+		} else {
+			maxTTL = b.MaxSessionTTLSeconds
+		}
+	}
+
+	instances := make(map[string]InstanceConfig, len(compute.Instances))
+	for _, instance := range compute.Instances {
+		instances[instance.Name] = instance
+	}
+
+	names := make(map[string]bool)
+	for i, session := range b.Sessions {
+		p := fmt.Sprintf("%s.sessions[%d]", path, i)
+		checkName(v, p+".name", session.Name, names)
+
+		instance, ok := instances[session.Instance]
+		if !ok {
+			v.addf(p+".instance", "instance %q is not defined in compute.instances", session.Instance)
+		}
+		switch session.Type {
+		case SessionManagedSSH:
+			if ok && !instance.BastionPlugin {
+				v.addf(p+".instance", "instance %q must enable bastion_plugin for managed SSH sessions", session.Instance)
+			}
+		case SessionPortForwarding:
+			if session.Username != "" {
+				v.addf(p+".username", "only applies to %s sessions", SessionManagedSSH)
+			}
+		default:
+			v.addf(p+".type", "%q must be %s or %s", session.Type, SessionManagedSSH, SessionPortForwarding)
+		}
+		if session.Port < 0 || session.Port > 65535 {
+			v.addf(p+".port", "%d is outside 1-65535", session.Port)
+		}
+		if session.SSHPublicKey == "" {
+			v.addf(p+".ssh_public_key", "is required")
+		}
+		if session.TTLSeconds != 0 && (session.TTLSeconds < MinSessionTTLSeconds || session.TTLSeconds > maxTTL) {
+			v.addf(p+".ttl_seconds", "must be between %d and the max_session_ttl_seconds of the bastion, %d", MinSessionTTLSeconds, maxTTL)
+		}
+	}
+}
+
+// alphanumeric reports whether s only contains ASCII letters and digits
+func alphanumeric(s string) bool {
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// MySQL DB system limits as enforced by OCI
+const (
+	minMySQLStorageGB     = 50
+	maxMySQLStorageGB     = 131072
+	maxBackupRetention    = 35
+	maxHeatwaveNodes      = 64
+	maxMySQLAdminUsername = 32
+)
+
+func (h HeatwaveConfig) validate(v *validator, path string, network NetworkConfig, compartments map[string]bool) {
+	h.BaseConfig.validate(v, path, h.Enabled(), compartments)
+
+	if !h.Enabled() {
+		if h.Name != "" || h.Shape != "" || h.AdminPasswordSecret != "" || h.Cluster != nil {
+			v.addf(path+".subnet", "is required to create a DB system")
+		}
+		return
+	}
+
+	if !network.subnetNames()[h.Subnet] {
+		v.addf(path+".subnet", "subnet %q is not defined in network.subnets", h.Subnet)
+	}
+	nsgs := network.nsgNames()
+	for i, nsg := range h.NSGs {
+		if !nsgs[nsg] {
+			v.addf(fmt.Sprintf("%s.nsgs[%d]", path, i), "network security group %q is not defined in network.network_security_groups", nsg)
+		}
+	}
+
+	required := []struct{ key, value string }{
+		{"name", h.Name},
+		{"availability_domain", h.AvailabilityDomain},
+		{"shape", h.Shape},
+		{"admin_password_secret", h.AdminPasswordSecret},
+	}
+	for _, field := range required {
+		if field.value == "" {
+			v.addf(path+"."+field.key, "is required")
+		}
+	}
+	if h.AdminUsername == "" {
+		v.addf(path+".admin_username", "is required")
+	} else if len(h.AdminUsername) > maxMySQLAdminUsername {
+		v.addf(path+".admin_username", "must be at most %d characters", maxMySQLAdminUsername)
+	}
+	if h.StorageSizeGB != 0 && (h.StorageSizeGB < minMySQLStorageGB || h.StorageSizeGB > maxMySQLStorageGB) {
+		v.addf(path+".storage_size_gb", "must be between %d and %d", minMySQLStorageGB, maxMySQLStorageGB)
+	}
+	if h.HostnameLabel != "" && !validHostnameLabel(h.HostnameLabel) {
+		v.addf(path+".hostname_label", "%q must be up to 63 letters, numbers and hyphens starting with a letter", h.HostnameLabel)
+	}
+	if h.MaintenanceWindow != "" && !validMaintenanceWindow(h.MaintenanceWindow) {
+		v.addf(path+".maintenance_window", "%q must be a day of the week followed by a UTC time, e.g. sun 02:00", h.MaintenanceWindow)
+	}
+
+	if b := h.Backup; b != nil {
+		if b.RetentionDays != 0 && (b.RetentionDays < 1 || b.RetentionDays > maxBackupRetention) {
+			v.addf(path+".backup.retention_days", "must be between 1 and %d", maxBackupRetention)
+		}
+		if b.WindowStartTime != "" {
+			if _, err := time.Parse("15:04", b.WindowStartTime); err != nil {
+				v.addf(path+".backup.window_start_time", "%q must be a UTC time, e.g. 01:00", b.WindowStartTime)
+			}
+		}
+		if b.PITR && b.Enabled != nil && !*b.Enabled {
+			v.addf(path+".backup.pitr", "point-in-time recovery requires backups to be enabled")
+		}
+	}
+
+	if c := h.Cluster; c != nil {
+		if c.Shape == "" {
+			v.addf(path+".cluster.shape", "is required")
+		}
+		if c.NodeCount < 1 || c.NodeCount > maxHeatwaveNodes {
+			v.addf(path+".cluster.node_count", "must be between 1 and %d", maxHeatwaveNodes)
+		}
+	}
+}
+
+// validHostnameLabel reports whether label is a valid hostname label: up to 63 letters, numbers and hyphens starting with a letter
+func validHostnameLabel(label string) bool {
+	if len(label) > 63 {
+		return false
+	}
+	for i, r := range label {
+		isLetter := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		isDigit := r >= '0' && r <= '9'
+		if !isLetter && (i == 0 || (!isDigit && r != '-')) {
+			return false
+		}
+	}
+	return true
+}
+
+// validMaintenanceWindow reports whether window is a day of the week followed by a time, e.g. sun 02:00
+func validMaintenanceWindow(window string) bool {
+	day, clock, ok := strings.Cut(window, " ")
+	if !ok {
+		return false
+	}
+	switch strings.ToLower(day) {
+	case "mon", "tue", "wed", "thu", "fri", "sat", "sun":
+	default:
+		return false
+	}
+	_, err := time.Parse("15:04", clock)
+	return err == nil
+}
+
+// subnetNames returns the set of subnet names defined in the network config
+func (n NetworkConfig) subnetNames() map[string]bool {
+	names := make(map[string]bool, len(n.Subnets))
+	for _, subnet := range n.Subnets {
+		names[subnet.Name] = true
+	}
+	return names
+}
+
+// nsgNames returns the set of network security group names defined in the network config
+func (n NetworkConfig) nsgNames() map[string]bool {
+	names := make(map[string]bool, len(n.NetworkSecurityGroups))
+	for _, nsg := range n.NetworkSecurityGroups {
+		names[nsg.DisplayName] = true
+	}
+	return names
+}
+
+// checkName reports an empty or duplicate name and records it in seen
+func checkName(v *validator, path, name string, seen map[string]bool) {
+	if name == "" {
+		v.addf(path, "is required")
+		return
+	}
+	if seen[name] {
+		v.addf(path, "%q is used more than once", name)
+	}
+	seen[name] = true
+}
+
+// parseCIDR parses an IPv4 or IPv6 CIDR block and reports it when invalid
+func parseCIDR(v *validator, path, cidr string) (netip.Prefix, bool) {
+	if cidr == "" {
+		v.addf(path, "is required")
+		return netip.Prefix{}, false
+	}
+
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		v.addf(path, "%q is not a valid CIDR block", cidr)
+		return netip.Prefix{}, false
+	}
+	if prefix != prefix.Masked() {
+		v.addf(path, "%q has host bits set, expected %s", cidr, prefix.Masked())
+		return netip.Prefix{}, false
+	}
+
+	return prefix, true
+}
+
+// containsPrefix reports whether inner lies completely inside outer
+func containsPrefix(outer, inner netip.Prefix) bool {
+	return outer.Bits() <= inner.Bits() && outer.Contains(inner.Addr())
+}
+
+// validateSecurityRule checks a rule of a grouped security list, which must match at least one
+// source (ingress) or destination (egress)
+func validateSecurityRule(v *validator, path, key, single string, list []string, rule SecurityRuleConfig) {
+	validateRuleOptions(v, path, rule.Protocol, rule.TCPOptions, rule.UDPOptions, rule.ICMPOptions)
+
+	if len(endpoints(single, list)) == 0 {
+		v.addf(path, "%s or %ss is required", key, key)
+	}
+	validateEndpoints(v, path, key, single, list)
+}
+
+// validateEndpoints checks the CIDR blocks of a single and a list valued endpoint key, e.g. source and sources
+func validateEndpoints(v *validator, path, key, single string, list []string) {
+	if isSet(single) {
+		parseCIDR(v, path+"."+key, single)
+	}
+	for j, cidr := range list {
+		parseCIDR(v, fmt.Sprintf("%s.%ss[%d]", path, key, j), cidr)
+	}
+}
+
+// validateRuleOptions checks the protocol of a security rule and that its port and ICMP options match it
+func validateRuleOptions(v *validator, path, protocol string, tcp []TCPOptionConfig, udp []UDPOptionConfig, icmp *ICMPOptionConfig) {
+	number, err := ProtocolNumber(protocol)
+	if err != nil {
+		v.addf(path+".protocol", "%v", err)
+	}
+
+	if len(tcp) > 0 && err == nil && number != ProtocolTCP {
+		v.addf(path+".tcp_options", "requires protocol tcp")
+	}
+	for i, opt := range tcp {
+		validatePortOption(v, fmt.Sprintf("%s.tcp_options[%d]", path, i), opt)
+	}
+
+	if len(udp) > 0 && err == nil && number != ProtocolUDP {
+		v.addf(path+".udp_options", "requires protocol udp")
+	}
+	for i, opt := range udp {
+		validatePortOption(v, fmt.Sprintf("%s.udp_options[%d]", path, i), opt)
+	}
+
+	if icmp != nil {
+		if err == nil && number != ProtocolICMP && number != ProtocolICMPv6 {
+			v.addf(path+".icmp_options", "requires protocol icmp or icmpv6")
+		}
+		if icmp.Type < 0 || icmp.Type > 255 {
+			v.addf(path+".icmp_options.type", "%d is outside 0-255", icmp.Type)
+		}
+		if icmp.Code != nil && (*icmp.Code < 0 || *icmp.Code > 255) {
+			v.addf(path+".icmp_options.code", "%d is outside 0-255", *icmp.Code)
+		}
+	}
+}
+
+// validatePortOption checks the destination and source port ranges of a tcp or udp option.
+// Either range may be left out, but not both.
+func validatePortOption(v *validator, path string, opt TCPOptionConfig) {
+	hasDestination := opt.MinPort != 0 || opt.MaxPort != 0
+	hasSource := opt.SourceMinPort != 0 || opt.SourceMaxPort != 0
+
+	if !hasDestination && !hasSource {
+		v.addf(path, "either min_port/max_port or source_min_port/source_max_port is required")
+		return
+	}
+	if hasDestination {
+		validatePortRange(v, path, "min_port", "max_port", opt.MinPort, opt.MaxPort)
+	}
+	if hasSource {
+		validatePortRange(v, path, "source_min_port", "source_max_port", opt.SourceMinPort, opt.SourceMaxPort)
+	}
+}
+
+// validatePortRange checks a single port range given the yaml keys of its bounds
+func validatePortRange(v *validator, path, minKey, maxKey string, min, max int) {
+	if min < 1 || min > 65535 {
+		v.addf(path+"."+minKey, "%d is outside 1-65535", min)
+	}
+	if max < 1 || max > 65535 {
+		v.addf(path+"."+maxKey, "%d is outside 1-65535", max)
+	}
+	if min > max {
+		v.addf(path, "%s %d is greater than %s %d", minKey, min, maxKey, max)
+	}
+}
+
+// validateDNSLabel checks an OCI DNS label: up to 15 alphanumeric characters starting with a letter
+func validateDNSLabel(v *validator, path, label string) {
+	valid := len(label) <= 15
+	for i, r := range label {
+		isLetter := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		isDigit := r >= '0' && r <= '9'
+		if !isLetter && (i == 0 || !isDigit) {
+			valid = false
+		}
+	}
+	if !valid {
+		v.addf(path, "%q must be up to 15 alphanumeric characters starting with a letter", label)
+	}
+}
+
+// isSet reports whether a source/destination value is present, treating the YAML "null" string as unset
+func isSet(value string) bool {
+	return value != "" && value != "null"
+}
@@ -0,0 +1,814 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// newValidConfig returns a config that passes Validate, to be modified by individual tests
+func newValidConfig() Config {
+	ocpus := 2.0
+	return Config{
+		Network: NetworkConfig{
+			BaseConfig:  BaseConfig{CompartmentID: "compartment-123"},
+			CidrBlock:   "10.0.0.0/16",
+			DisplayName: "test-vcn",
+			DNSLabel:    "testvcn",
+			Subnets: []SubnetConfig{
+				{Name: "public-subnet", CidrBlock: "10.0.1.0/24", Public: true, DNSLabel: "public", RouteTable: "public-routes"},
+				{Name: "private-subnet", CidrBlock: "10.0.2.0/24", DNSLabel: "private", DHCPOptions: "custom-dns"},
+			},
+			DHCPOptions: []DHCPOptionsConfig{
+				{Name: "custom-dns", ServerType: "CustomDnsServer", CustomDNSServers: []string{"10.0.0.2"}},
+			},
+			Gateways: []GatewayConfig{
+				{Name: "internet-gateway", Type: "internet"},
+				{Name: "service-gateway", Type: "service", Services: "objectstorage"},
+			},
+			RouteTables: []RouteTableConfig{
+				{
+					Name: "public-routes",
+					Rules: []RouteRuleConfig{
+						{Destination: "0.0.0.0/0", Gateway: "internet-gateway"},
+						{Gateway: "service-gateway"},
+					},
+				},
+			},
+			SecurityLists: []SecurityListConfig{
+				{
+					DisplayName: "public-ingress",
+					SubnetName:  "public-subnet",
+					Protocol:    "6",
+					Source:      "0.0.0.0/0",
+					TCPOptions:  []TCPOptionConfig{{MinPort: 22, MaxPort: 22}},
+				},
+				{
+					DisplayName: "public-egress",
+					SubnetName:  "public-subnet",
+					Protocol:    "all",
+					Destination: "0.0.0.0/0",
+				},
+			},
+			NetworkSecurityGroups: []NetworkSecurityGroupConfig{
+				{
+					DisplayName: "app-nsg",
+					Rules: []NSGRuleConfig{
+						{Direction: "egress", Protocol: "all", Destination: "0.0.0.0/0"},
+					},
+				},
+				{
+					DisplayName: "db-nsg",
+					Rules: []NSGRuleConfig{
+						{Direction: "ingress", Protocol: "6", Source: "app-nsg", TCPOptions: []TCPOptionConfig{{MinPort: 1521, MaxPort: 1521}}},
+					},
+				},
+			},
+		},
+		Compute: ComputeConfig{
+			BaseConfig: BaseConfig{CompartmentID: "compartment-123"},
+			Instances: []InstanceConfig{
+				{
+					Name:         "instance-1",
+					Shape:        "VM.Standard.E4.Flex",
+					SubnetID:     "subnet-123",
+					ImageOCID:    "ocid1.image.oc1..example",
+					SSHPublicKey: "ssh-rsa AAAAB3NzaC1yc2E...",
+					OCPUCount:    &ocpus,
+					NSGs:         []string{"app-nsg"},
+				},
+			},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assignPublicIP := true
+	tests := []struct {
+		name          string
+		modify        func(c *Config)
+		expectedPaths []string
+	}{
+		{
+			name:          "Valid configuration",
+			modify:        func(c *Config) {},
+			expectedPaths: nil,
+		},
+		{
+			name:          "Invalid VCN CIDR",
+			modify:        func(c *Config) { c.Network.CidrBlock = "10.0.0.0/33" },
+			expectedPaths: []string{"network.cidr_block"},
+		},
+		{
+			name:          "Subnet outside VCN",
+			modify:        func(c *Config) { c.Network.Subnets[1].CidrBlock = "192.168.0.0/24" },
+			expectedPaths: []string{"network.subnets[1].cidr_block"},
+		},
+		{
+			name:          "Overlapping subnets",
+			modify:        func(c *Config) { c.Network.Subnets[1].CidrBlock = "10.0.0.0/20" },
+			expectedPaths: []string{"network.subnets[1].cidr_block"},
+		},
+		{
+			name:          "Subnet with host bits set",
+			modify:        func(c *Config) { c.Network.Subnets[0].CidrBlock = "10.0.1.1/24" },
+			expectedPaths: []string{"network.subnets[0].cidr_block"},
+		},
+		{
+			name:          "Duplicate subnet name",
+			modify:        func(c *Config) { c.Network.Subnets[1].Name = "public-subnet" },
+			expectedPaths: []string{"network.subnets[1].name"},
+		},
+		{
+			name:          "Unknown subnet_name",
+			modify:        func(c *Config) { c.Network.SecurityLists[0].SubnetName = "missing-subnet" },
+			expectedPaths: []string{"network.security_lists[0].subnet_name"},
+		},
+		{
+			name:          "Invalid protocol",
+			modify:        func(c *Config) { c.Network.SecurityLists[0].Protocol = "tcp6" },
+			expectedPaths: []string{"network.security_lists[0].protocol"},
+		},
+		{
+			name:          "Missing source and destination",
+			modify:        func(c *Config) { c.Network.SecurityLists[0].Source = "null" },
+			expectedPaths: []string{"network.security_lists[0]"},
+		},
+		{
+			name: "Invalid port range",
+			modify: func(c *Config) {
+				c.Network.SecurityLists[0].TCPOptions = []TCPOptionConfig{{MinPort: 443, MaxPort: 80}, {MinPort: 0, MaxPort: 70000}}
+			},
+			expectedPaths: []string{
+				"network.security_lists[0].tcp_options[0]",
+				"network.security_lists[0].tcp_options[1].min_port",
+				"network.security_lists[0].tcp_options[1].max_port",
+			},
+		},
+		{
+			name:          "Symbolic protocol",
+			modify:        func(c *Config) { c.Network.SecurityLists[0].Protocol = "tcp" },
+			expectedPaths: nil,
+		},
+		{
+			name:          "TCP options with udp protocol",
+			modify:        func(c *Config) { c.Network.SecurityLists[0].Protocol = "udp" },
+			expectedPaths: []string{"network.security_lists[0].tcp_options"},
+		},
+		{
+			name: "UDP options with source ports",
+			modify: func(c *Config) {
+				c.Network.SecurityLists[0].Protocol = "udp"
+				c.Network.SecurityLists[0].TCPOptions = nil
+				c.Network.SecurityLists[0].UDPOptions = []UDPOptionConfig{{SourceMinPort: 123, SourceMaxPort: 123}, {}}
+			},
+			expectedPaths: []string{"network.security_lists[0].udp_options[1]"},
+		},
+		{
+			name: "ICMP options",
+			modify: func(c *Config) {
+				code := 256
+				c.Network.NetworkSecurityGroups[0].Rules[0].ICMPOptions = &ICMPOptionConfig{Type: 3, Code: &code}
+			},
+			expectedPaths: []string{
+				"network.network_security_groups[0].rules[0].icmp_options",
+				"network.network_security_groups[0].rules[0].icmp_options.code",
+			},
+		},
+		{
+			name: "Multiple sources",
+			modify: func(c *Config) {
+				c.Network.SecurityLists[0].Source = ""
+				c.Network.SecurityLists[0].Sources = []string{"10.0.0.0/16", "192.168.0.0/33"}
+			},
+			expectedPaths: []string{"network.security_lists[0].sources[1]"},
+		},
+		{
+			name: "Grouped security list",
+			modify: func(c *Config) {
+				c.Network.SecurityLists = append(c.Network.SecurityLists, SecurityListConfig{
+					DisplayName:  "private-security-list",
+					SubnetName:   "private-subnet",
+					IngressRules: []SecurityRuleConfig{{Protocol: "udp", Sources: []string{"10.0.0.0/16"}, UDPOptions: []UDPOptionConfig{{MinPort: 53, MaxPort: 53}}}},
+					EgressRules:  []SecurityRuleConfig{{Protocol: "all", Destination: "0.0.0.0/0"}},
+				})
+			},
+			expectedPaths: nil,
+		},
+		{
+			name: "Invalid grouped rules",
+			modify: func(c *Config) {
+				c.Network.SecurityLists = append(c.Network.SecurityLists, SecurityListConfig{
+					DisplayName:  "private-security-list",
+					IngressRules: []SecurityRuleConfig{{Protocol: "tcp", Destination: "0.0.0.0/0"}},
+					EgressRules:  []SecurityRuleConfig{{Protocol: "icmp", Destination: "0.0.0.0/33"}},
+				})
+			},
+			expectedPaths: []string{
+				"network.security_lists[2].ingress_rules[0]",
+				"network.security_lists[2].egress_rules[0].destination",
+			},
+		},
+		{
+			name: "Flat fields mixed with grouped rules",
+			modify: func(c *Config) {
+				c.Network.SecurityLists[0].EgressRules = []SecurityRuleConfig{{Protocol: "all", Destination: "0.0.0.0/0"}}
+			},
+			expectedPaths: []string{"network.security_lists[0]"},
+		},
+		{
+			name: "Merged security list name collision",
+			modify: func(c *Config) {
+				c.Network.SecurityLists = append(c.Network.SecurityLists, SecurityListConfig{
+					DisplayName: "public-subnet-security-list",
+					EgressRules: []SecurityRuleConfig{{Protocol: "all", Destination: "0.0.0.0/0"}},
+				})
+			},
+			expectedPaths: []string{"network.security_lists"},
+		},
+		{
+			name: "Too many security lists on a subnet",
+			modify: func(c *Config) {
+				for i := 0; i < 5; i++ {
+					c.Network.SecurityLists = append(c.Network.SecurityLists, SecurityListConfig{
+						DisplayName: fmt.Sprintf("extra-%d", i),
+						SubnetName:  "public-subnet",
+						EgressRules: []SecurityRuleConfig{{Protocol: "all", Destination: "0.0.0.0/0"}},
+					})
+				}
+			},
+			expectedPaths: []string{"network.security_lists"},
+		},
+		{
+			name: "Duplicate instance and missing fields",
+			modify: func(c *Config) {
+				c.Compute.Instances = append(c.Compute.Instances, InstanceConfig{Name: "instance-1"})
+			},
+			expectedPaths: []string{
+				"compute.instances[1].name",
+				"compute.instances[1].shape",
+				"compute.instances[1].subnet",
+				"compute.instances[1].image_ocid",
+				"compute.instances[1].ssh_public_key",
+			},
+		},
+		{
+			name:          "Instance subnet by name",
+			modify:        func(c *Config) { c.Compute.Instances[0].Subnet, c.Compute.Instances[0].SubnetID = "private-subnet", "" },
+			expectedPaths: nil,
+		},
+		{
+			name:          "Instance subnet unknown",
+			modify:        func(c *Config) { c.Compute.Instances[0].Subnet, c.Compute.Instances[0].SubnetID = "missing-subnet", "" },
+			expectedPaths: []string{"compute.instances[0].subnet"},
+		},
+		{
+			name:          "Instance subnet and subnet_id",
+			modify:        func(c *Config) { c.Compute.Instances[0].Subnet = "private-subnet" },
+			expectedPaths: []string{"compute.instances[0].subnet"},
+		},
+		{
+			name:          "Unknown NSG reference in rule",
+			modify:        func(c *Config) { c.Network.NetworkSecurityGroups[1].Rules[0].Source = "web-nsg" },
+			expectedPaths: []string{"network.network_security_groups[1].rules[0].source"},
+		},
+		{
+			name:          "Invalid NSG rule direction",
+			modify:        func(c *Config) { c.Network.NetworkSecurityGroups[0].Rules[0].Direction = "both" },
+			expectedPaths: []string{"network.network_security_groups[0].rules[0].direction"},
+		},
+		{
+			name:          "Unknown instance NSG",
+			modify:        func(c *Config) { c.Compute.Instances[0].NSGs = []string{"app-nsg", "web-nsg"} },
+			expectedPaths: []string{"compute.instances[0].nsgs[1]"},
+		},
+		{
+			name:          "Second internet gateway",
+			modify:        func(c *Config) { c.Network.Gateways[1].Type, c.Network.Gateways[1].Services = "internet", "" },
+			expectedPaths: []string{"network.gateways[1].type", "network.route_tables[0].rules[1].destination"},
+		},
+		{
+			name:   "Unknown gateway type and services",
+			modify: func(c *Config) { c.Network.Gateways[1].Type = "drg" },
+			expectedPaths: []string{
+				"network.gateways[1].type",
+				"network.gateways[1].services",
+				"network.route_tables[0].rules[1].destination",
+			},
+		},
+		{
+			name:          "Route rule to unknown gateway",
+			modify:        func(c *Config) { c.Network.RouteTables[0].Rules[0].Gateway = "nat-gateway" },
+			expectedPaths: []string{"network.route_tables[0].rules[0].gateway"},
+		},
+		{
+			name:          "Route rule without destination",
+			modify:        func(c *Config) { c.Network.RouteTables[0].Rules[0].Destination = "" },
+			expectedPaths: []string{"network.route_tables[0].rules[0].destination"},
+		},
+		{
+			name:          "Unknown subnet route table",
+			modify:        func(c *Config) { c.Network.Subnets[1].RouteTable = "private-routes" },
+			expectedPaths: []string{"network.subnets[1].route_table"},
+		},
+		{
+			name:          "Invalid DNS labels",
+			modify:        func(c *Config) { c.Network.DNSLabel, c.Network.Subnets[0].DNSLabel = "1vcn", "public-subnet-label" },
+			expectedPaths: []string{"network.dns_label", "network.subnets[0].dns_label"},
+		},
+		{
+			name:          "Subnet DNS label without VCN DNS label",
+			modify:        func(c *Config) { c.Network.DNSLabel = "" },
+			expectedPaths: []string{"network.subnets[0].dns_label", "network.subnets[1].dns_label"},
+		},
+		{
+			name:          "Duplicate subnet DNS label",
+			modify:        func(c *Config) { c.Network.Subnets[1].DNSLabel = "public" },
+			expectedPaths: []string{"network.subnets[1].dns_label"},
+		},
+		{
+			name:          "Unknown subnet dhcp options",
+			modify:        func(c *Config) { c.Network.Subnets[1].DHCPOptions = "corp-dns" },
+			expectedPaths: []string{"network.subnets[1].dhcp_options"},
+		},
+		{
+			name: "Invalid custom DNS servers",
+			modify: func(c *Config) {
+				c.Network.DHCPOptions[0].CustomDNSServers = []string{"10.0.0.2", "dns.example.com", "10.0.0.4", "10.0.0.5"}
+			},
+			expectedPaths: []string{"network.dhcp_options[0].custom_dns_servers", "network.dhcp_options[0].custom_dns_servers[1]"},
+		},
+		{
+			name: "Public IP in private subnet",
+			modify: func(c *Config) {
+				c.Compute.Instances[0].Subnet, c.Compute.Instances[0].SubnetID, c.Compute.Instances[0].AssignPublicIP = "private-subnet", "", &assignPublicIP
+			},
+			expectedPaths: []string{"compute.instances[0].assign_public_ip"},
+		},
+		{
+			name: "Public IP in public subnet",
+			modify: func(c *Config) {
+				c.Compute.Instances[0].Subnet, c.Compute.Instances[0].SubnetID, c.Compute.Instances[0].AssignPublicIP = "public-subnet", "", &assignPublicIP
+			},
+			expectedPaths: nil,
+		},
+		{
+			name: "Availability and fault domains",
+			modify: func(c *Config) {
+				c.Compute.Instances[0].AvailabilityDomain, c.Compute.Instances[0].FaultDomain = "Uocm:PHX-AD-2", "fault-domain-3"
+				c.Compute.Spread = SpreadAvailabilityDomains
+			},
+			expectedPaths: nil,
+		},
+		{
+			name: "Image selector",
+			modify: func(c *Config) {
+				c.Compute.Instances[0].ImageOCID = ""
+				c.Compute.Instances[0].Image = &ImageConfig{OperatingSystem: "Oracle Linux", Version: "8", DisplayName: `^Oracle-Linux-8\.\d+-\d{4}`, Date: "2024-01-26"}
+			},
+			expectedPaths: nil,
+		},
+		{
+			name: "Invalid image selector",
+			modify: func(c *Config) {
+				c.Compute.Instances[0].ImageOCID = ""
+				c.Compute.Instances[0].Image = &ImageConfig{DisplayName: "Oracle-Linux-(8", Date: "2024.01.26"}
+			},
+			expectedPaths: []string{"compute.instances[0].image.operating_system", "compute.instances[0].image.display_name", "compute.instances[0].image.date"},
+		},
+		{
+			name: "Inline cloud-config template",
+			modify: func(c *Config) {
+				c.Compute.Instances[0].UserData = &UserDataConfig{
+					Inline:   "#cloud-config\nwrite_files:\n  - path: /etc/app.env\n    content: |\n      DB_HOST={{ output \"heatwave.host\" }}\n",
+					Template: true,
+				}
+			},
+			expectedPaths: nil,
+		},
+		{
+			name: "Shell script user data",
+			modify: func(c *Config) {
+				c.Compute.Instances[0].UserData = &UserDataConfig{Inline: "#!/bin/sh\necho {{ broken"}
+			},
+			expectedPaths: nil,
+		},
+		{
+			name: "Broken cloud-config",
+			modify: func(c *Config) {
+				c.Compute.Instances[0].UserData = &UserDataConfig{Inline: "#cloud-config\npackages: [git\n"}
+			},
+			expectedPaths: []string{"compute.instances[0].user_data"},
+		},
+		{
+			name: "Broken user data template",
+			modify: func(c *Config) {
+				c.Compute.Instances[0].UserData = &UserDataConfig{Inline: "#cloud-config\nhostname: {{ output }}\n", Template: true}
+			},
+			expectedPaths: []string{"compute.instances[0].user_data"},
+		},
+		{
+			name: "User data inline and file",
+			modify: func(c *Config) {
+				c.Compute.Instances[0].UserData = &UserDataConfig{Inline: "#cloud-config\n", File: "cloud-init/app.yaml"}
+			},
+			expectedPaths: []string{"compute.instances[0].user_data"},
+		},
+		{
+			name: "User data file not loaded",
+			modify: func(c *Config) {
+				c.Compute.Instances[0].UserData = &UserDataConfig{File: "cloud-init/app.yaml"}
+			},
+			expectedPaths: []string{"compute.instances[0].user_data.file"},
+		},
+		{
+			name: "Boot and data volumes",
+			modify: func(c *Config) {
+				vpus, bootVPUs := 0, 20
+				c.Compute.Instances[0].BootVolumeSizeGB, c.Compute.Instances[0].BootVolumeVPUs = 100, &bootVPUs
+				c.Compute.Instances[0].Volumes = []BlockVolumeConfig{
+					{Name: "data", SizeGB: 500, VPUs: &vpus, Attachment: AttachmentISCSI, Device: "/dev/oracleoci/oraclevdb", BackupPolicy: BackupPolicySilver},
+					{Name: "cache", SizeGB: 50, Device: "/dev/oracleoci/oraclevdc", BackupPolicy: "ocid1.volumebackuppolicy.oc1..example"},
+				}
+			},
+			expectedPaths: nil,
+		},
+		{
+			name: "Invalid boot and data volumes",
+			modify: func(c *Config) {
+				vpus, bootVPUs := 15, 0
+				c.Compute.Instances[0].BootVolumeSizeGB, c.Compute.Instances[0].BootVolumeVPUs = 40, &bootVPUs
+				c.Compute.Instances[0].Volumes = []BlockVolumeConfig{
+					{Name: "data", SizeGB: 40000, VPUs: &vpus, Attachment: "nvme", Device: "/dev/oracleoci/oraclevda", BackupPolicy: "platinum"},
+					{Name: "data", SizeGB: 50, Device: "/dev/sdb"},
+					{Name: "logs", SizeGB: 50, Device: "/dev/oracleoci/oraclevdb"},
+					{Name: "cache", SizeGB: 50, Device: "/dev/oracleoci/oraclevdb"},
+				}
+			},
+			expectedPaths: []string{
+				"compute.instances[0].boot_volume_size_gb",
+				"compute.instances[0].boot_volume_vpus_per_gb",
+				"compute.instances[0].volumes[0].size_gb",
+				"compute.instances[0].volumes[0].vpus_per_gb",
+				"compute.instances[0].volumes[0].attachment",
+				"compute.instances[0].volumes[0].device",
+				"compute.instances[0].volumes[0].backup_policy",
+				"compute.instances[0].volumes[1].name",
+				"compute.instances[0].volumes[1].device",
+				"compute.instances[0].volumes[3].device",
+			},
+		},
+		{
+			name: "Instance pool",
+			modify: func(c *Config) {
+				template := c.Compute.Instances[0]
+				template.Name = ""
+				c.Compute.Pools = []InstancePoolConfig{{Name: "web", Size: 3, Template: template, AvailabilityDomains: []string{"1", "Uocm:PHX-AD-2"}, FaultDomains: []string{"1", "FAULT-DOMAIN-2"}}}
+			},
+			expectedPaths: nil,
+		},
+		{
+			name: "Invalid instance pools",
+			modify: func(c *Config) {
+				template := c.Compute.Instances[0]
+				template.AvailabilityDomain = "1"
+				template.Volumes = []BlockVolumeConfig{{Name: "data", SizeGB: 50}}
+				c.Compute.Pools = []InstancePoolConfig{
+					{Name: "web", Size: -1, Template: template, AvailabilityDomains: []string{"0"}, FaultDomains: []string{"4"}},
+					{Name: "web", Template: InstanceConfig{Shape: "VM.Standard.E4.Flex", SubnetID: "subnet-123", ImageOCID: "ocid1.image.oc1..example"}},
+				}
+			},
+			expectedPaths: []string{
+				"compute.pools[0].size",
+				"compute.pools[0].template",
+				"compute.pools[0].template.volumes",
+				"compute.pools[0].availability_domains[0]",
+				"compute.pools[0].fault_domains[0]",
+				"compute.pools[1].name",
+				"compute.pools[1].template.ssh_public_key",
+			},
+		},
+		{
+			name: "Invalid availability and fault domains",
+			modify: func(c *Config) {
+				c.Compute.Instances[0].AvailabilityDomain, c.Compute.Instances[0].FaultDomain = "0", "FAULT-DOMAIN-4"
+				c.Compute.Spread = "regions"
+			},
+			expectedPaths: []string{"compute.instances[0].availability_domain", "compute.instances[0].fault_domain", "compute.spread"},
+		},
+		{
+			name: "Compartment by name",
+			modify: func(c *Config) {
+				c.Identity = IdentityConfig{
+					TenancyID:    "ocid1.tenancy.oc1..example",
+					Compartments: []CompartmentConfig{{Name: "infra", Description: "Infra", Compartments: []CompartmentConfig{{Name: "network", Description: "Network"}}}},
+				}
+				c.Network.CompartmentID, c.Network.Compartment = "", "network"
+			},
+			expectedPaths: nil,
+		},
+		{
+			name: "Unknown compartment and both compartment keys",
+			modify: func(c *Config) {
+				c.Network.CompartmentID, c.Network.Compartment = "", "network"
+				c.Compute.Compartment = "compute"
+			},
+			expectedPaths: []string{"network.compartment", "compute.compartment", "compute.compartment"},
+		},
+		{
+			name: "Invalid compartment tree",
+			modify: func(c *Config) {
+				c.Identity = IdentityConfig{
+					Compartments: []CompartmentConfig{{Name: "infra", Compartments: []CompartmentConfig{{Name: "infra", Description: "Nested"}}}},
+				}
+			},
+			expectedPaths: []string{
+				"identity.tenancy_id",
+				"identity.compartments[0].description",
+				"identity.compartments[0].compartments[0].name",
+			},
+		},
+		{
+			name: "Bastion with sessions",
+			modify: func(c *Config) {
+				c.Compute.Instances[0].BastionPlugin = true
+				c.Bastion = BastionConfig{
+					BaseConfig:           BaseConfig{CompartmentID: "compartment-123"},
+					Name:                 "devbastion",
+					Subnet:               "private-subnet",
+					ClientCIDRs:          []string{"203.0.113.0/24"},
+					MaxSessionTTLSeconds: 3600,
+					Sessions: []BastionSessionConfig{
+						{Name: "ssh", Type: SessionManagedSSH, Instance: c.Compute.Instances[0].Name, SSHPublicKey: "ssh-rsa AAAA"},
+						{Name: "forward", Type: SessionPortForwarding, Instance: c.Compute.Instances[0].Name, Port: 8080, SSHPublicKey: "ssh-rsa AAAA", TTLSeconds: 1800},
+					},
+				}
+			},
+			expectedPaths: nil,
+		},
+		{
+			name: "Bastion without subnet",
+			modify: func(c *Config) {
+				c.Bastion.Name = "devbastion"
+			},
+			expectedPaths: []string{"bastion.subnet"},
+		},
+		{
+			name: "Invalid bastion",
+			modify: func(c *Config) {
+				c.Bastion = BastionConfig{
+					Name:                 "dev-bastion",
+					Subnet:               "missing-subnet",
+					ClientCIDRs:          []string{"203.0.113.1/24"},
+					MaxSessionTTLSeconds: 60,
+					Sessions: []BastionSessionConfig{
+						{Name: "ssh", Type: SessionManagedSSH, Instance: c.Compute.Instances[0].Name, TTLSeconds: 1800},
+						{Name: "ssh", Type: "rdp", Instance: "missing", Port: 70000, SSHPublicKey: "ssh-rsa AAAA"},
+						{Name: "forward", Type: SessionPortForwarding, Instance: c.Compute.Instances[0].Name, Username: "opc", SSHPublicKey: "ssh-rsa AAAA", TTLSeconds: 20000},
+					},
+				}
+			},
+			expectedPaths: []string{
+				"bastion.compartment_id",
+				"bastion.subnet",
+				"bastion.name",
+				"bastion.client_cidrs[0]",
+				"bastion.max_session_ttl_seconds",
+				"bastion.sessions[0].instance",
+				"bastion.sessions[0].ssh_public_key",
+				"bastion.sessions[1].name",
+				"bastion.sessions[1].instance",
+				"bastion.sessions[1].type",
+				"bastion.sessions[1].port",
+				"bastion.sessions[2].username",
+				"bastion.sessions[2].ttl_seconds",
+			},
+		},
+		{
+			name: "HeatWave DB system",
+			modify: func(c *Config) {
+				c.Heatwave = HeatwaveConfig{
+					BaseConfig:          BaseConfig{CompartmentID: "compartment-123"},
+					Name:                "appdb",
+					Subnet:              "private-subnet",
+					AvailabilityDomain:  "Uocm:PHX-AD-1",
+					Shape:               "MySQL.2",
+					StorageSizeGB:       50,
+					HostnameLabel:       "app-db",
+					AdminUsername:       "admin",
+					AdminPasswordSecret: "mysqlAdminPassword",
+					MaintenanceWindow:   "SUN 02:00",
+					Backup:              &MySQLBackupConfig{RetentionDays: 35, WindowStartTime: "01:30", PITR: true},
+					Cluster:             &HeatwaveClusterConfig{Shape: "HeatWave.512GB", NodeCount: 1},
+				}
+			},
+			expectedPaths: nil,
+		},
+		{
+			name: "HeatWave without subnet",
+			modify: func(c *Config) {
+				c.Heatwave.Shape = "MySQL.2"
+			},
+			expectedPaths: []string{"heatwave.subnet"},
+		},
+		{
+			name: "Invalid HeatWave DB system",
+			modify: func(c *Config) {
+				disabled := false
+				c.Heatwave = HeatwaveConfig{
+					Subnet:            "missing-subnet",
+					NSGs:              []string{"missing-nsg"},
+					AdminUsername:     "a-very-long-admin-username-over-32",
+					StorageSizeGB:     10,
+					HostnameLabel:     "1db",
+					MaintenanceWindow: "sunday 2am",
+					Backup:            &MySQLBackupConfig{Enabled: &disabled, RetentionDays: 40, WindowStartTime: "25:00", PITR: true},
+					Cluster:           &HeatwaveClusterConfig{NodeCount: 65},
+				}
+			},
+			expectedPaths: []string{
+				"heatwave.compartment_id",
+				"heatwave.subnet",
+				"heatwave.nsgs[0]",
+				"heatwave.name",
+				"heatwave.availability_domain",
+				"heatwave.shape",
+				"heatwave.admin_password_secret",
+				"heatwave.admin_username",
+				"heatwave.storage_size_gb",
+				"heatwave.hostname_label",
+				"heatwave.maintenance_window",
+				"heatwave.backup.retention_days",
+				"heatwave.backup.window_start_time",
+				"heatwave.backup.pitr",
+				"heatwave.cluster.shape",
+				"heatwave.cluster.node_count",
+			},
+		},
+		{
+			name: "Storage buckets",
+			modify: func(c *Config) {
+				c.Storage = StorageConfig{
+					BaseConfig: BaseConfig{CompartmentID: "compartment-123"},
+					NamePrefix: "dev",
+					Buckets: []BucketConfig{
+						{Name: "backups", Versioning: VersioningEnabled, LifecycleRules: []LifecycleRuleConfig{
+							{Name: "expire", Action: LifecycleDelete, Target: LifecycleTargetPreviousVersions, After: DurationConfig{Amount: 30, Unit: "days"}},
+						}},
+						{Name: "logs", RetentionRules: []RetentionRuleConfig{
+							{Name: "keep", Duration: &DurationConfig{Amount: 1, Unit: "YEARS"}, TimeRuleLocked: "2030-01-01T00:00:00Z"},
+						}},
+					},
+				}
+			},
+			expectedPaths: nil,
+		},
+		{
+			name: "Invalid storage buckets",
+			modify: func(c *Config) {
+				c.Storage = StorageConfig{
+					Buckets: []BucketConfig{
+						{Name: "bad name", AccessType: "Public", StorageTier: "Cold", Versioning: "On", KMSKeyID: "key"},
+						{Name: "bad name"},
+						{Name: "versioned", Versioning: VersioningEnabled, RetentionRules: []RetentionRuleConfig{
+							{Name: "forever", TimeRuleLocked: "tomorrow"},
+							{Name: "forever", Duration: &DurationConfig{Amount: 0, Unit: "weeks"}},
+						}},
+						{Name: "archive", StorageTier: StorageTierArchive, LifecycleRules: []LifecycleRuleConfig{
+							{Name: "archive", Action: LifecycleArchive, After: DurationConfig{Amount: 1, Unit: "days"}},
+							{Name: "abort", Action: LifecycleAbort, After: DurationConfig{Amount: 1, Unit: "days"}},
+							{Name: "uploads", Action: LifecycleDelete, Target: LifecycleTargetMultipartUploads, After: DurationConfig{Amount: 1, Unit: "days"}},
+							{Name: "target", Action: LifecycleDelete, Target: "everything", After: DurationConfig{Amount: 1, Unit: "days"}},
+							{Name: "action", Action: "MOVE", After: DurationConfig{Amount: 1, Unit: "days"}},
+						}},
+					},
+				}
+			},
+			expectedPaths: []string{
+				"storage.compartment_id",
+				"storage.buckets[0].name",
+				"storage.buckets[0].access_type",
+				"storage.buckets[0].storage_tier",
+				"storage.buckets[0].versioning",
+				"storage.buckets[0].kms_key_id",
+				"storage.buckets[1].name",
+				"storage.buckets[1].name",
+				"storage.buckets[2].retention_rules",
+				"storage.buckets[2].retention_rules[0].time_rule_locked",
+				"storage.buckets[2].retention_rules[0].time_rule_locked",
+				"storage.buckets[2].retention_rules[1].name",
+				"storage.buckets[2].retention_rules[1].duration.amount",
+				"storage.buckets[2].retention_rules[1].duration.unit",
+				"storage.buckets[3].lifecycle_rules[0].action",
+				"storage.buckets[3].lifecycle_rules[1].action",
+				"storage.buckets[3].lifecycle_rules[2].action",
+				"storage.buckets[3].lifecycle_rules[3].target",
+				"storage.buckets[3].lifecycle_rules[4].action",
+			},
+		},
+		{
+			name: "IAM groups and policies",
+			modify: func(c *Config) {
+				c.Identity = IdentityConfig{
+					TenancyID:     "ocid1.tenancy.oc1..example",
+					Compartments:  []CompartmentConfig{{Name: "infra", Description: "Infra"}},
+					Groups:        []GroupConfig{{Name: "operators", Description: "Operators"}},
+					DynamicGroups: []DynamicGroupConfig{{Name: "instances", Description: "Instances", Match: &DynamicGroupMatchConfig{Compartments: []string{"infra"}}}},
+					Policies: []PolicyConfig{{
+						Name:        "operators",
+						Description: "Operators",
+						Statements:  []string{`Allow group operators to manage all-resources in compartment {{ compartment "infra" }}`},
+					}},
+				}
+			},
+			expectedPaths: nil,
+		},
+		{
+			name: "Invalid IAM groups and policies",
+			modify: func(c *Config) {
+				c.Identity = IdentityConfig{
+					Groups: []GroupConfig{{Name: "operators"}, {Name: "operators", Description: "Duplicate"}},
+					DynamicGroups: []DynamicGroupConfig{
+						{Name: "none", Description: "No rule"},
+						{Name: "both", Description: "Both", MatchingRule: "ANY {}", Match: &DynamicGroupMatchConfig{}},
+						{Name: "unknown", Description: "Unknown", Match: &DynamicGroupMatchConfig{All: true, Compartments: []string{"missing"}, CompartmentIDs: []string{"ocid1.compartment.oc1..a"}, Tags: []TagMatchConfig{{Key: "role"}}}},
+					},
+					Policies: []PolicyConfig{
+						{Name: "unknown", Description: "Unknown", Compartment: "missing", Statements: []string{"Allow any-user to read buckets in tenancy"}},
+						{Name: "syntax", Description: "Syntax", Statements: []string{"Allow group operators to manage all-resources", `Allow any-user to read buckets in compartment {{ compartment "missing" }}`}},
+						{Name: "empty", Description: "Empty"},
+					},
+				}
+			},
+			expectedPaths: []string{
+				"identity.tenancy_id",
+				"identity.groups[0].description",
+				"identity.groups[1].name",
+				"identity.dynamic_groups[0]",
+				"identity.dynamic_groups[1]",
+				"identity.dynamic_groups[2].match.compartments[0]",
+				"identity.dynamic_groups[2].match.tags[0].namespace",
+				"identity.dynamic_groups[2].match",
+				"identity.policies[0].compartment",
+				"identity.policies[1].statements[0]",
+				"identity.policies[1].statements[1]",
+				"identity.policies[2].statements",
+			},
+		},
+		{
+			name:          "Region without compartment",
+			modify:        func(c *Config) { c.Bastion.Region = "eu-frankfurt-1" },
+			expectedPaths: []string{"bastion.compartment_id"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newValidConfig()
+			tt.modify(&cfg)
+
+			err := cfg.Validate()
+			if len(tt.expectedPaths) == 0 {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+
+			var verrs ValidationErrors
+			if !errors.As(err, &verrs) {
+				t.Fatalf("Expected ValidationErrors, but got %v", err)
+			}
+			if len(verrs) != len(tt.expectedPaths) {
+				t.Fatalf("Expected %d errors, but got %d: %v", len(tt.expectedPaths), len(verrs), err)
+			}
+			for i, path := range tt.expectedPaths {
+				if verrs[i].Path != path {
+					t.Errorf("Expected error %d at %s, but got %s", i, path, verrs[i].Path)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRepositoryEnvironments(t *testing.T) {
+	for _, env := range AvailableEnvironments(".") {
+		t.Run(env, func(t *testing.T) {
+			paths, err := LayerPaths(".", env)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var cfg Config
+			if err := cfg.LoadLayered(paths...); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if err := cfg.LoadUserData("."); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if err := cfg.Validate(); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ module infra
 go 1.25.0
 
 require (
-	github.com/pulumi/pulumi-oci/sdk v1.41.0
 	github.com/pulumi/pulumi-oci/sdk/v3 v3.13.0
 	github.com/pulumi/pulumi/sdk/v3 v3.214.0
 	gopkg.in/yaml.v3 v3.0.1
@@ -66,6 +65,7 @@ require (
 	github.com/santhosh-tekuri/jsonschema/v5 v5.0.0 // indirect
 	github.com/sergi/go-diff v1.3.2-0.20230802210424-5b0b94c5c0d3 // indirect
 	github.com/skeema/knownhosts v1.3.0 // indirect
+	github.com/spf13/cast v1.4.1 // indirect
 	github.com/spf13/cobra v1.8.0 // indirect
 	github.com/spf13/pflag v1.0.10 // indirect
 	github.com/texttheater/golang-levenshtein v1.0.1 // indirect
@@ -0,0 +1,154 @@
+// Package heatwave creates the MySQL HeatWave DB system described by the heatwave section of the config
+package heatwave
+
+import (
+	"fmt"
+	"infra/config"
+
+	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/core"
+	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/mysql"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	pulumiconfig "github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
+)
+
+// OutputHeatwave is the stack export key of the DB system endpoint
+const OutputHeatwave = "heatwave"
+
+// HeatwaveCfg wraps the HeatwaveConfig and provides methods for managing the DB system
+type HeatwaveCfg struct {
+	config.HeatwaveConfig
+	// Subnets holds the subnets created in this program keyed by their config name
+	Subnets map[string]*core.Subnet
+	// NetworkSecurityGroups holds the network security groups created in this program keyed by display name
+	NetworkSecurityGroups map[string]*core.NetworkSecurityGroup
+	// Compartments resolves compartment references by name, see config.BaseConfig.CompartmentIDInput
+	Compartments map[string]pulumi.StringInput
+}
+
+// DBSystem is a created DB system with its optional HeatWave cluster
+type DBSystem struct {
+	DBSystem *mysql.MysqlDbSystem
+	Cluster  *mysql.HeatWaveCluster
+}
+
+// CreateDBSystem creates the DB system in the configured subnet and its HeatWave cluster. It returns nil
+// when the config does not describe a DB system.
+func (h *HeatwaveCfg) CreateDBSystem(ctx *pulumi.Context) (*DBSystem, error) {
+	if !h.Enabled() {
+		return nil, nil
+	}
+
+	subnet, ok := h.Subnets[h.Subnet]
+	if !ok || subnet == nil {
+		return nil, fmt.Errorf("subnet %s not found", h.Subnet)
+	}
+
+	nsgIDs := make(pulumi.StringArray, 0, len(h.NSGs))
+	for _, name := range h.NSGs {
+		nsg, ok := h.NetworkSecurityGroups[name]
+		if !ok || nsg == nil {
+			return nil, fmt.Errorf("network security group %s not found", name)
+		}
+		nsgIDs = append(nsgIDs, nsg.ID().ToStringOutput())
+	}
+
+	compartmentID, err := h.CompartmentIDInput(h.Compartments)
+	if err != nil {
+		return nil, err
+	}
+
+	adminPassword, err := pulumiconfig.TrySecret(ctx, h.AdminPasswordSecret)
+	if err != nil {
+		return nil, fmt.Errorf("admin password secret %s: %w", h.AdminPasswordSecret, err)
+	}
+
+	args := &mysql.MysqlDbSystemArgs{
+		CompartmentId:      compartmentID,
+		DisplayName:        pulumi.String(h.Name),
+		SubnetId:           subnet.ID().ToStringOutput(),
+		AvailabilityDomain: pulumi.String(h.AvailabilityDomain),
+		ShapeName:          pulumi.String(h.Shape),
+		AdminUsername:      pulumi.String(h.AdminUsername),
+		AdminPassword:      adminPassword,
+		IsHighlyAvailable:  pulumi.Bool(h.HighlyAvailable),
+	}
+	if h.Backup != nil {
+		args.BackupPolicy = backupPolicyArgs(*h.Backup)
+	}
+	if len(nsgIDs) > 0 {
+		args.NsgIds = nsgIDs
+	}
+	if h.MySQLVersion != "" {
+		args.MysqlVersion = pulumi.String(h.MySQLVersion)
+	}
+	if h.StorageSizeGB != 0 {
+		args.DataStorageSizeInGb = pulumi.Int(h.StorageSizeGB)
+	}
+	if h.HostnameLabel != "" {
+		args.HostnameLabel = pulumi.String(h.HostnameLabel)
+	}
+	if h.MaintenanceWindow != "" {
+		args.Maintenance = &mysql.MysqlDbSystemMaintenanceArgs{
+			WindowStartTime: pulumi.String(h.MaintenanceWindow),
+		}
+	}
+
+	dbSystem, err := mysql.NewMysqlDbSystem(ctx, h.Name, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DB system %s: %w", h.Name, err)
+	}
+	if h.Cluster == nil {
+		return &DBSystem{DBSystem: dbSystem}, nil
+	}
+
+	cluster, err := mysql.NewHeatWaveCluster(ctx, h.Name+"-heatwave", &mysql.HeatWaveClusterArgs{
+		DbSystemId:         dbSystem.ID().ToStringOutput(),
+		ShapeName:          pulumi.String(h.Cluster.Shape),
+		ClusterSize:        pulumi.Int(h.Cluster.NodeCount),
+		IsLakehouseEnabled: pulumi.Bool(h.Cluster.Lakehouse),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HeatWave cluster for %s: %w", h.Name, err)
+	}
+
+	return &DBSystem{DBSystem: dbSystem, Cluster: cluster}, nil
+}
+
+// backupPolicyArgs converts the backup config, leaving unset values to the OCI defaults
+func backupPolicyArgs(backup config.MySQLBackupConfig) *mysql.MysqlDbSystemBackupPolicyArgs {
+	enabled := backup.Enabled == nil || *backup.Enabled
+	args := &mysql.MysqlDbSystemBackupPolicyArgs{
+		IsEnabled: pulumi.Bool(enabled),
+		PitrPolicy: &mysql.MysqlDbSystemBackupPolicyPitrPolicyArgs{
+			IsEnabled: pulumi.Bool(backup.PITR),
+		},
+	}
+	if backup.RetentionDays != 0 {
+		args.RetentionInDays = pulumi.Int(backup.RetentionDays)
+	}
+	if backup.WindowStartTime != "" {
+		args.WindowStartTime = pulumi.String(backup.WindowStartTime)
+	}
+	return args
+}
+
+// Outputs returns the DB system ID and the endpoint applications connect to: the private IP address,
+// the hostname when a hostname label is set, and the MySQL classic and X protocol ports
+func (d *DBSystem) Outputs() pulumi.Map {
+	hostname := d.DBSystem.Endpoints.ApplyT(func(endpoints []mysql.MysqlDbSystemEndpoint) string {
+		for _, endpoint := range endpoints {
+			if endpoint.Hostname != nil {
+				return *endpoint.Hostname
+			}
+		}
+		return ""
+	}).(pulumi.StringOutput)
+
+	return pulumi.Map{
+		"id":       d.DBSystem.ID().ToStringOutput(),
+		"host":     d.DBSystem.IpAddress,
+		"hostname": hostname,
+		"port":     d.DBSystem.Port,
+		"port_x":   d.DBSystem.PortX,
+	}
+}
@@ -0,0 +1,181 @@
+package heatwave
+
+import (
+	"infra/config"
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/core"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// HeatwaveMocks records the inputs of every created resource by name
+type HeatwaveMocks struct {
+	mu        sync.Mutex
+	resources map[string]resource.PropertyMap
+}
+
+func (m *HeatwaveMocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	m.mu.Lock()
+	m.resources[args.Name] = args.Inputs
+	m.mu.Unlock()
+
+	outputs := args.Inputs.Copy()
+	if args.TypeToken == "oci:Mysql/mysqlDbSystem:MysqlDbSystem" {
+		outputs["ipAddress"] = resource.NewStringProperty("10.0.3.10")
+		outputs["port"] = resource.NewNumberProperty(3306)
+		outputs["portX"] = resource.NewNumberProperty(33060)
+	}
+	return args.Name + "_id", outputs, nil
+}
+
+func (m *HeatwaveMocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+	return args.Args, nil
+}
+
+func newHeatwaveMocks() *HeatwaveMocks {
+	return &HeatwaveMocks{resources: make(map[string]resource.PropertyMap)}
+}
+
+func newTestHeatwaveCfg() HeatwaveCfg {
+	enabled := true
+	return HeatwaveCfg{
+		HeatwaveConfig: config.HeatwaveConfig{
+			BaseConfig:          config.BaseConfig{CompartmentID: "ocid1.compartment.oc1..example"},
+			Name:                "appdb",
+			Subnet:              "database-subnet",
+			NSGs:                []string{"db-nsg"},
+			AvailabilityDomain:  "Uocm:PHX-AD-1",
+			Shape:               "MySQL.2",
+			StorageSizeGB:       100,
+			AdminUsername:       "admin",
+			AdminPasswordSecret: "mysqlAdminPassword",
+			HighlyAvailable:     true,
+			MaintenanceWindow:   "sun 02:00",
+			Backup:              &config.MySQLBackupConfig{Enabled: &enabled, RetentionDays: 14, WindowStartTime: "01:00", PITR: true},
+			Cluster:             &config.HeatwaveClusterConfig{Shape: "HeatWave.512GB", NodeCount: 2},
+		},
+	}
+}
+
+// createTestDBSystem creates the subnet and network security group referenced by the test config, then the DB system
+func createTestDBSystem(ctx *pulumi.Context, hcfg *HeatwaveCfg) (*DBSystem, error) {
+	subnet, err := core.NewSubnet(ctx, "database-subnet", &core.SubnetArgs{
+		CompartmentId: pulumi.String("ocid1.compartment.oc1..example"),
+		VcnId:         pulumi.String("vcn_id"),
+		CidrBlock:     pulumi.String("10.0.3.0/24"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	nsg, err := core.NewNetworkSecurityGroup(ctx, "db-nsg", &core.NetworkSecurityGroupArgs{
+		CompartmentId: pulumi.String("ocid1.compartment.oc1..example"),
+		VcnId:         pulumi.String("vcn_id"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hcfg.Subnets = map[string]*core.Subnet{"database-subnet": subnet}
+	hcfg.NetworkSecurityGroups = map[string]*core.NetworkSecurityGroup{"db-nsg": nsg}
+	return hcfg.CreateDBSystem(ctx)
+}
+
+func TestCreateDBSystem(t *testing.T) {
+	t.Setenv(pulumi.EnvConfig, `{"project:mysqlAdminPassword": "s3cret!"}`)
+	t.Setenv(pulumi.EnvConfigSecretKeys, `["project:mysqlAdminPassword"]`)
+
+	hcfg := newTestHeatwaveCfg()
+	mocks := newHeatwaveMocks()
+
+	var wg sync.WaitGroup
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		dbSystem, err := createTestDBSystem(ctx, &hcfg)
+		if err != nil {
+			return err
+		}
+		if dbSystem == nil || dbSystem.Cluster == nil {
+			t.Fatalf("Expected a DB system with a HeatWave cluster, but got %v", dbSystem)
+		}
+
+		wg.Add(1)
+		dbSystem.Outputs().ToMapOutput().ApplyT(func(outputs map[string]interface{}) error {
+			defer wg.Done()
+			if outputs["host"] != "10.0.3.10" || outputs["port"] != 3306 || outputs["port_x"] != 33060 {
+				t.Errorf("Expected endpoint 10.0.3.10:3306/33060, but got %v", outputs)
+			}
+			return nil
+		})
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	wg.Wait()
+
+	inputs := mocks.resources["appdb"]
+	expected := map[string]interface{}{
+		"subnetId":            "database-subnet_id",
+		"availabilityDomain":  "Uocm:PHX-AD-1",
+		"shapeName":           "MySQL.2",
+		"dataStorageSizeInGb": float64(100),
+		"adminUsername":       "admin",
+		"isHighlyAvailable":   true,
+	}
+	for key, value := range expected {
+		if got := inputs[resource.PropertyKey(key)].V; got != value {
+			t.Errorf("Expected %s to be %v, but got %v", key, value, got)
+		}
+	}
+
+	password := inputs["adminPassword"]
+	if !password.IsSecret() || password.SecretValue().Element.StringValue() != "s3cret!" {
+		t.Errorf("Expected the admin password to be passed as a secret, but got %v", password)
+	}
+	if nsgs := inputs["nsgIds"].ArrayValue(); len(nsgs) != 1 || nsgs[0].StringValue() != "db-nsg_id" {
+		t.Errorf("Expected NSG db-nsg_id, but got %v", nsgs)
+	}
+
+	backup := inputs["backupPolicy"].ObjectValue()
+	if backup["retentionInDays"].NumberValue() != 14 || backup["windowStartTime"].StringValue() != "01:00" || !backup["pitrPolicy"].ObjectValue()["isEnabled"].BoolValue() {
+		t.Errorf("Expected a 14 day backup policy at 01:00 with PITR, but got %v", backup)
+	}
+	if got := inputs["maintenance"].ObjectValue()["windowStartTime"].StringValue(); got != "sun 02:00" {
+		t.Errorf("Expected maintenance window sun 02:00, but got %s", got)
+	}
+
+	cluster := mocks.resources["appdb-heatwave"]
+	if cluster["dbSystemId"].StringValue() != "appdb_id" || cluster["clusterSize"].NumberValue() != 2 {
+		t.Errorf("Expected a 2 node cluster on appdb_id, but got %v", cluster)
+	}
+}
+
+func TestCreateDBSystemMissingSecret(t *testing.T) {
+	t.Setenv(pulumi.EnvConfig, `{}`)
+	hcfg := newTestHeatwaveCfg()
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		_, err := createTestDBSystem(ctx, &hcfg)
+		return err
+	}, pulumi.WithMocks("project", "stack", newHeatwaveMocks()))
+
+	if err == nil {
+		t.Errorf("Expected error but got none")
+	}
+}
+
+func TestCreateDBSystemDisabled(t *testing.T) {
+	hcfg := HeatwaveCfg{}
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		dbSystem, err := hcfg.CreateDBSystem(ctx)
+		if dbSystem != nil {
+			t.Errorf("Expected no DB system, but got %v", dbSystem)
+		}
+		return err
+	}, pulumi.WithMocks("project", "stack", newHeatwaveMocks()))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package identity
+
+import (
+	"sort"
+
+	ociidentity "github.com/pulumi/pulumi-oci/sdk/v3/go/oci/identity"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// AvailabilityDomainNames looks up the availability domains of the region visible to compartmentID,
+// usually the tenancy, and returns their names sorted so that AD-1 comes first
+func AvailabilityDomainNames(ctx *pulumi.Context, compartmentID pulumi.StringInput) pulumi.StringArrayOutput {
+	result := ociidentity.GetAvailabilityDomainsOutput(ctx, ociidentity.GetAvailabilityDomainsOutputArgs{
+		CompartmentId: compartmentID,
+	})
+	return result.AvailabilityDomains().ApplyT(func(availabilityDomains []ociidentity.GetAvailabilityDomainsAvailabilityDomain) []string {
+		names := make([]string, 0, len(availabilityDomains))
+		for _, ad := range availabilityDomains {
+			names = append(names, ad.Name)
+		}
+		sort.Strings(names)
+		return names
+	}).(pulumi.StringArrayOutput)
+}
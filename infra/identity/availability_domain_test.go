@@ -0,0 +1,59 @@
+package identity
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// AvailabilityDomainMocks answers the availability domain lookup with unsorted names
+type AvailabilityDomainMocks struct {
+	*IdentityMocks
+}
+
+func (m *AvailabilityDomainMocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+	if args.Token != "oci:Identity/getAvailabilityDomains:getAvailabilityDomains" {
+		return args.Args, nil
+	}
+	var availabilityDomains []interface{}
+	for _, name := range []string{"Uocm:PHX-AD-2", "Uocm:PHX-AD-1", "Uocm:PHX-AD-3"} {
+		availabilityDomains = append(availabilityDomains, map[string]interface{}{"name": name})
+	}
+	return resource.NewPropertyMapFromMap(map[string]interface{}{
+		"compartmentId":       args.Args["compartmentId"],
+		"availabilityDomains": availabilityDomains,
+	}), nil
+}
+
+func TestAvailabilityDomainNames(t *testing.T) {
+	mocks := &AvailabilityDomainMocks{IdentityMocks: newIdentityMocks()}
+	var wg sync.WaitGroup
+	var names []string
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		wg.Add(1)
+		AvailabilityDomainNames(ctx, pulumi.String("ocid1.tenancy.oc1..example")).ApplyT(func(v []string) error {
+			names = v
+			wg.Done()
+			return nil
+		})
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	wg.Wait()
+
+	expected := []string{"Uocm:PHX-AD-1", "Uocm:PHX-AD-2", "Uocm:PHX-AD-3"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected %v, but got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("Expected %v, but got %v", expected, names)
+			break
+		}
+	}
+}
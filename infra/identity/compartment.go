@@ -0,0 +1,60 @@
+// Package identity creates the OCI identity resources described by the identity section of the config
+package identity
+
+import (
+	"fmt"
+	"infra/config"
+
+	ociidentity "github.com/pulumi/pulumi-oci/sdk/v3/go/oci/identity"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// IdentityCfg wraps the IdentityConfig and provides methods for managing identity resources
+type IdentityCfg struct {
+	config.IdentityConfig
+}
+
+// CreateCompartments creates the compartment tree below the tenancy, parents before children,
+// and returns the compartments keyed by name
+func (i *IdentityCfg) CreateCompartments(ctx *pulumi.Context) (map[string]*ociidentity.Compartment, error) {
+	compartments := make(map[string]*ociidentity.Compartment)
+	if err := i.createCompartments(ctx, i.Compartments, pulumi.String(i.TenancyID), compartments); err != nil {
+		return nil, err
+	}
+	return compartments, nil
+}
+
+// createCompartments creates a level of the compartment tree below parentID and recurses into the children
+func (i *IdentityCfg) createCompartments(ctx *pulumi.Context, level []config.CompartmentConfig, parentID pulumi.StringInput, compartments map[string]*ociidentity.Compartment) error {
+	for _, v := range level {
+		if _, exists := compartments[v.Name]; exists {
+			return fmt.Errorf("compartment %s is defined more than once", v.Name)
+		}
+
+		compartment, err := ociidentity.NewCompartment(ctx, v.Name, &ociidentity.CompartmentArgs{
+			CompartmentId: parentID,
+			Name:          pulumi.String(v.Name),
+			Description:   pulumi.String(v.Description),
+			EnableDelete:  pulumi.Bool(v.EnableDelete),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create compartment %s: %w", v.Name, err)
+		}
+		compartments[v.Name] = compartment
+
+		if err := i.createCompartments(ctx, v.Compartments, compartment.ID().ToStringOutput(), compartments); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CompartmentIDs returns the IDs of the given compartments keyed by name, as referenced by
+// compartment in the other sections of the config
+func CompartmentIDs(compartments map[string]*ociidentity.Compartment) map[string]pulumi.StringInput {
+	ids := make(map[string]pulumi.StringInput, len(compartments))
+	for name, compartment := range compartments {
+		ids[name] = compartment.ID().ToStringOutput()
+	}
+	return ids
+}
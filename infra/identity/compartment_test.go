@@ -0,0 +1,106 @@
+package identity
+
+import (
+	"infra/config"
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// IdentityMocks records the inputs of every created resource by name
+type IdentityMocks struct {
+	mu        sync.Mutex
+	resources map[string]resource.PropertyMap
+}
+
+func (m *IdentityMocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	m.mu.Lock()
+	m.resources[args.Name] = args.Inputs
+	m.mu.Unlock()
+	return args.Name + "_id", args.Inputs, nil
+}
+
+func (m *IdentityMocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+	return args.Args, nil
+}
+
+func newIdentityMocks() *IdentityMocks {
+	return &IdentityMocks{resources: make(map[string]resource.PropertyMap)}
+}
+
+func TestCreateCompartments(t *testing.T) {
+	icfg := IdentityCfg{
+		IdentityConfig: config.IdentityConfig{
+			TenancyID: "ocid1.tenancy.oc1..example",
+			Compartments: []config.CompartmentConfig{
+				{
+					Name:        "infra",
+					Description: "Infrastructure",
+					Compartments: []config.CompartmentConfig{
+						{Name: "network", Description: "Networking", EnableDelete: true},
+						{Name: "compute", Description: "Compute"},
+					},
+				},
+			},
+		},
+	}
+	mocks := newIdentityMocks()
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		compartments, err := icfg.CreateCompartments(ctx)
+		if err != nil {
+			return err
+		}
+		if len(compartments) != 3 {
+			t.Errorf("Expected 3 compartments, but got %d", len(compartments))
+		}
+		if ids := CompartmentIDs(compartments); len(ids) != 3 || ids["network"] == nil {
+			t.Errorf("Expected compartment IDs keyed by name, but got %v", ids)
+		}
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	parents := map[string]string{
+		"infra":   "ocid1.tenancy.oc1..example",
+		"network": "infra_id",
+		"compute": "infra_id",
+	}
+	for name, parent := range parents {
+		inputs, ok := mocks.resources[name]
+		if !ok {
+			t.Errorf("Expected compartment %s to be created", name)
+			continue
+		}
+		if got := inputs["compartmentId"].StringValue(); got != parent {
+			t.Errorf("Expected %s parent to be %s, but got %s", name, parent, got)
+		}
+	}
+	if !mocks.resources["network"]["enableDelete"].BoolValue() {
+		t.Errorf("Expected network enable_delete to be true")
+	}
+}
+
+func TestCreateCompartmentsDuplicateName(t *testing.T) {
+	icfg := IdentityCfg{
+		IdentityConfig: config.IdentityConfig{
+			TenancyID: "ocid1.tenancy.oc1..example",
+			Compartments: []config.CompartmentConfig{
+				{Name: "infra", Description: "Infrastructure", Compartments: []config.CompartmentConfig{{Name: "infra", Description: "Nested"}}},
+			},
+		},
+	}
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		_, err := icfg.CreateCompartments(ctx)
+		return err
+	}, pulumi.WithMocks("project", "stack", newIdentityMocks()))
+
+	if err == nil {
+		t.Errorf("Expected error but got none")
+	}
+}
@@ -0,0 +1,103 @@
+package identity
+
+import (
+	"fmt"
+
+	ociidentity "github.com/pulumi/pulumi-oci/sdk/v3/go/oci/identity"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// CreateGroups creates the IAM groups in the root compartment of the tenancy and returns them keyed by name
+func (i *IdentityCfg) CreateGroups(ctx *pulumi.Context) (map[string]*ociidentity.Group, error) {
+	groups := make(map[string]*ociidentity.Group, len(i.Groups))
+	for _, v := range i.Groups {
+		group, err := ociidentity.NewGroup(ctx, v.Name, &ociidentity.GroupArgs{
+			CompartmentId: pulumi.String(i.TenancyID),
+			Name:          pulumi.String(v.Name),
+			Description:   pulumi.String(v.Description),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create group %s: %w", v.Name, err)
+		}
+		groups[v.Name] = group
+	}
+	return groups, nil
+}
+
+// CreateDynamicGroups creates the dynamic groups in the root compartment of the tenancy and returns them
+// keyed by name. Generated matching rules resolve compartment names through compartmentIDs.
+func (i *IdentityCfg) CreateDynamicGroups(ctx *pulumi.Context, compartmentIDs map[string]pulumi.StringInput) (map[string]*ociidentity.DynamicGroup, error) {
+	groups := make(map[string]*ociidentity.DynamicGroup, len(i.DynamicGroups))
+	for _, v := range i.DynamicGroups {
+		var matchingRule pulumi.StringInput = pulumi.String(v.MatchingRule)
+		if v.Match != nil {
+			match := *v.Match
+
+			ids := make([]interface{}, 0, len(match.Compartments)+len(match.CompartmentIDs))
+			for _, name := range match.Compartments {
+				id, ok := compartmentIDs[name]
+				if !ok {
+					return nil, fmt.Errorf("dynamic group %s: compartment %s not found", v.Name, name)
+				}
+				ids = append(ids, id)
+			}
+			for _, id := range match.CompartmentIDs {
+				ids = append(ids, pulumi.String(id))
+			}
+
+			matchingRule = pulumi.All(ids...).ApplyT(func(resolved []interface{}) string {
+				ocids := make([]string, 0, len(resolved))
+				for _, id := range resolved {
+					ocids = append(ocids, id.(string))
+				}
+				return match.MatchingRule(ocids)
+			}).(pulumi.StringOutput)
+		}
+
+		group, err := ociidentity.NewDynamicGroup(ctx, v.Name, &ociidentity.DynamicGroupArgs{
+			CompartmentId: pulumi.String(i.TenancyID),
+			Name:          pulumi.String(v.Name),
+			Description:   pulumi.String(v.Description),
+			MatchingRule:  matchingRule,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create dynamic group %s: %w", v.Name, err)
+		}
+		groups[v.Name] = group
+	}
+	return groups, nil
+}
+
+// CreatePolicies renders the statements of every policy and creates it in its compartment, or in the root
+// compartment of the tenancy. Pass pulumi.DependsOn with the groups the statements refer to, OCI rejects
+// statements naming groups that do not exist yet.
+func (i *IdentityCfg) CreatePolicies(ctx *pulumi.Context, compartmentIDs map[string]pulumi.StringInput, opts ...pulumi.ResourceOption) (map[string]*ociidentity.Policy, error) {
+	policies := make(map[string]*ociidentity.Policy, len(i.Policies))
+	for _, v := range i.Policies {
+		var compartmentID pulumi.StringInput = pulumi.String(i.TenancyID)
+		if v.Compartment != "" {
+			id, ok := compartmentIDs[v.Compartment]
+			if !ok {
+				return nil, fmt.Errorf("policy %s: compartment %s not found", v.Name, v.Compartment)
+			}
+			compartmentID = id
+		}
+
+		statements, err := i.PolicyStatements(v)
+		if err != nil {
+			return nil, fmt.Errorf("policy %s: %w", v.Name, err)
+		}
+
+		policy, err := ociidentity.NewPolicy(ctx, v.Name, &ociidentity.PolicyArgs{
+			CompartmentId: compartmentID,
+			Name:          pulumi.String(v.Name),
+			Description:   pulumi.String(v.Description),
+			Statements:    pulumi.ToStringArray(statements),
+		}, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create policy %s: %w", v.Name, err)
+		}
+		policies[v.Name] = policy
+	}
+	return policies, nil
+}
@@ -0,0 +1,104 @@
+package identity
+
+import (
+	"infra/config"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+func newTestIAMCfg() IdentityCfg {
+	return IdentityCfg{
+		IdentityConfig: config.IdentityConfig{
+			TenancyID: "ocid1.tenancy.oc1..example",
+			Compartments: []config.CompartmentConfig{
+				{Name: "infra", Description: "Infrastructure", Compartments: []config.CompartmentConfig{{Name: "compute", Description: "Compute"}}},
+			},
+			Groups: []config.GroupConfig{{Name: "operators", Description: "Operators"}},
+			DynamicGroups: []config.DynamicGroupConfig{
+				{
+					Name:        "instances",
+					Description: "Compute instances",
+					Match: &config.DynamicGroupMatchConfig{
+						Compartments: []string{"compute"},
+						Tags:         []config.TagMatchConfig{{Namespace: "ops", Key: "role", Value: "web"}},
+					},
+				},
+			},
+			Policies: []config.PolicyConfig{
+				{
+					Name:        "instances-objects",
+					Description: "Instance principal access to objects",
+					Compartment: "infra",
+					Statements:  []string{`Allow dynamic-group instances to manage objects in compartment {{ compartment "compute" }}`},
+				},
+			},
+		},
+	}
+}
+
+func TestCreateIAM(t *testing.T) {
+	icfg := newTestIAMCfg()
+	mocks := newIdentityMocks()
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		compartments, err := icfg.CreateCompartments(ctx)
+		if err != nil {
+			return err
+		}
+		compartmentIDs := CompartmentIDs(compartments)
+
+		groups, err := icfg.CreateGroups(ctx)
+		if err != nil {
+			return err
+		}
+		dynamicGroups, err := icfg.CreateDynamicGroups(ctx, compartmentIDs)
+		if err != nil {
+			return err
+		}
+		policies, err := icfg.CreatePolicies(ctx, compartmentIDs, pulumi.DependsOn([]pulumi.Resource{groups["operators"], dynamicGroups["instances"]}))
+		if err != nil {
+			return err
+		}
+
+		if len(groups) != 1 || len(dynamicGroups) != 1 || len(policies) != 1 {
+			t.Errorf("Expected 1 group, dynamic group and policy, but got %d, %d and %d", len(groups), len(dynamicGroups), len(policies))
+		}
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := mocks.resources["operators"]["compartmentId"].StringValue(); got != "ocid1.tenancy.oc1..example" {
+		t.Errorf("Expected group in the tenancy, but got %s", got)
+	}
+
+	expectedRule := "ANY {instance.compartment.id = 'compute_id', tag.ops.role.value = 'web'}"
+	if got := mocks.resources["instances"]["matchingRule"].StringValue(); got != expectedRule {
+		t.Errorf("Expected matching rule %q, but got %q", expectedRule, got)
+	}
+
+	policy := mocks.resources["instances-objects"]
+	if got := policy["compartmentId"].StringValue(); got != "infra_id" {
+		t.Errorf("Expected policy in compartment infra_id, but got %s", got)
+	}
+	statements := policy["statements"].ArrayValue()
+	expectedStatement := "Allow dynamic-group instances to manage objects in compartment compute"
+	if len(statements) != 1 || statements[0].StringValue() != expectedStatement {
+		t.Errorf("Expected statement %q, but got %v", expectedStatement, statements)
+	}
+}
+
+func TestCreatePoliciesUnknownCompartment(t *testing.T) {
+	icfg := newTestIAMCfg()
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		_, err := icfg.CreatePolicies(ctx, map[string]pulumi.StringInput{})
+		return err
+	}, pulumi.WithMocks("project", "stack", newIdentityMocks()))
+
+	if err == nil {
+		t.Errorf("Expected error but got none")
+	}
+}
@@ -1,13 +1,15 @@
 package main
 
 import (
+	"infra/bastion"
 	"infra/compute"
 	"infra/config"
+	"infra/heatwave"
+	"infra/identity"
 	"infra/network"
+	"infra/storage"
 	"log"
 
-	"github.com/pulumi/pulumi-oci/sdk/go/oci/identity"
-	"github.com/pulumi/pulumi-oci/sdk/go/oci/objectstorage"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
 
@@ -15,79 +17,120 @@ import (
 func main() {
 	pulumi.Run(func(ctx *pulumi.Context) error {
 		var cfg config.Config
-		err := cfg.LoadFromYaml("config/dev/config.yaml")
+		err := cfg.LoadForStack(ctx)
 		if err != nil {
 			return err
 		}
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
 
-		ncfg := network.NetCfg{NetworkConfig: cfg.Network}
-		vcn, err := ncfg.CreateVCN(ctx, ncfg.DisplayName)
+		// Create the compartment tree first, every other section may reference a compartment by name
+		icfg := identity.IdentityCfg{IdentityConfig: cfg.Identity}
+		compartments, err := icfg.CreateCompartments(ctx)
+		if err != nil {
+			log.Printf("Failed to create compartments with error: %v", err)
+			return err
+		}
+		compartmentIDs := identity.CompartmentIDs(compartments)
 
+		// Groups and dynamic groups must exist before the policies that name them
+		groups, err := icfg.CreateGroups(ctx)
+		if err != nil {
+			log.Printf("Failed to create groups with error: %v", err)
+			return err
+		}
+		dynamicGroups, err := icfg.CreateDynamicGroups(ctx, compartmentIDs)
+		if err != nil {
+			log.Printf("Failed to create dynamic groups with error: %v", err)
+			return err
+		}
+		var principals []pulumi.Resource
+		for _, group := range groups {
+			principals = append(principals, group)
+		}
+		for _, group := range dynamicGroups {
+			principals = append(principals, group)
+		}
+		if _, err := icfg.CreatePolicies(ctx, compartmentIDs, pulumi.DependsOn(principals)); err != nil {
+			log.Printf("Failed to create policies with error: %v", err)
+			return err
+		}
+
+		// Create the VCN with all of its networking resources as children of one component
+		ncfg := network.NetCfg{NetworkConfig: cfg.Network, Compartments: compartmentIDs}
+		vcn, err := network.NewVcn(ctx, ncfg.DisplayName, &ncfg)
 		if err != nil {
 			log.Printf("Failed to create VCN with error: %v", err)
 			return err
 		}
 
-		// Create security lists and get a map for easy reference
-		securityLists, err := ncfg.CreateACLMap(ctx, vcn.ID().ElementType().String())
+		// The DB system endpoint is exported for the application instances to connect to
+		hcfg := heatwave.HeatwaveCfg{HeatwaveConfig: cfg.Heatwave, Subnets: vcn.Subnets, NetworkSecurityGroups: vcn.NSGs, Compartments: compartmentIDs}
+		dbSystem, err := hcfg.CreateDBSystem(ctx)
 		if err != nil {
-			log.Printf("Failed to create security lists with error: %v", err)
+			log.Printf("Failed to create MySQL DB system with error: %v", err)
 			return err
 		}
+		if dbSystem != nil {
+			ctx.Export(heatwave.OutputHeatwave, dbSystem.Outputs())
+		}
 
-		// Create subnets with their respective security lists attached based on subnet_name configuration
-		subnets, err := ncfg.CreateAllSubnetsWithSecurityLists(ctx, vcn.ID().ElementType().String(), securityLists)
+		// Create the buckets, instances reach them through the instance principal policies of the identity section
+		scfg := storage.StorageCfg{StorageConfig: cfg.Storage, Compartments: compartmentIDs}
+		buckets, err := scfg.CreateBuckets(ctx)
 		if err != nil {
-			log.Printf("Failed to create subnets with security lists with error: %v", err)
+			log.Printf("Failed to create buckets with error: %v", err)
 			return err
 		}
+		ctx.Export(storage.OutputBuckets, storage.Outputs(buckets))
 
-		// Export subnet IDs for reference
-		for i, subnet := range subnets {
-			ctx.Export("subnet-"+string(rune(i)), subnet.ID())
+		// User data templates of the instances look up the DB system endpoint and the buckets
+		stackOutputs := pulumi.Map{storage.OutputBuckets: storage.Outputs(buckets)}
+		if dbSystem != nil {
+			stackOutputs[heatwave.OutputHeatwave] = dbSystem.Outputs()
 		}
 
-		ccfg := compute.ComputeCfg{ComputeConfig: cfg.Compute}
-		instances, err := ccfg.CreateAllInstances(ctx)
+		// Availability domains are listed in the tenancy, or in the compute compartment without a tenancy_id
+		adCompartmentID, err := cfg.Compute.CompartmentIDInput(compartmentIDs)
 		if err != nil {
-			log.Printf("Failed to create compute instances with error: %v", err)
 			return err
 		}
-		for i, instance := range instances {
-			ctx.Export("instance-"+string(rune(i)), instance.ID())
+		if cfg.Identity.TenancyID != "" {
+			adCompartmentID = pulumi.String(cfg.Identity.TenancyID)
 		}
-
-		myCompartment, err := identity.NewCompartment(ctx, "my-compartment", &identity.CompartmentArgs{
-			Name:         pulumi.String("my-compartment"),
-			Description:  pulumi.String("My description text"),
-			EnableDelete: pulumi.Bool(true),
-		})
+		ccfg := compute.ComputeCfg{
+			ComputeConfig:       cfg.Compute,
+			Subnets:             vcn.Subnets,
+			NSGs:                vcn.NSGs,
+			Compartments:        compartmentIDs,
+			AvailabilityDomains: identity.AvailabilityDomainNames(ctx, adCompartmentID),
+			StackOutputs:        stackOutputs,
+		}
+		fleet, err := compute.NewFleet(ctx, "instances", &ccfg)
 		if err != nil {
+			log.Printf("Failed to create compute instances with error: %v", err)
 			return err
 		}
 
-		myNamespace := pulumi.All(myCompartment.CompartmentId).ApplyT(
-			func(args []interface{}) (string, error) {
-				namespace, err := objectstorage.GetNamespace(ctx, &objectstorage.GetNamespaceArgs{
-					CompartmentId: pulumi.StringRef(args[0].(string)),
-				})
-				if err != nil {
-					return "", err
-				}
-				return namespace.Namespace, nil
-			},
-		).(pulumi.StringOutput)
-
-		myBucket, err := objectstorage.NewBucket(ctx, "my-bucket", &objectstorage.BucketArgs{
-			Name:          pulumi.String("my-bucket"),
-			Namespace:     myNamespace,
-			CompartmentId: myCompartment.ID(),
-		})
+		// The bastion replaces public SSH, sessions reach the instances of the fleet by config name
+		bcfg := bastion.BastionCfg{BastionConfig: cfg.Bastion, Subnets: vcn.Subnets, Instances: fleet.Instances, Compartments: compartmentIDs}
+		bst, err := bcfg.CreateBastion(ctx)
 		if err != nil {
+			log.Printf("Failed to create bastion with error: %v", err)
 			return err
 		}
+		if bst != nil {
+			ctx.Export(bastion.OutputBastion, bst.Outputs())
+		}
 
-		ctx.Export("name", myBucket.Name)
+		// Export structured outputs keyed by config names for StackReference consumers
+		for key, value := range vcn.Outputs() {
+			ctx.Export(key, value)
+		}
+		for key, value := range fleet.Outputs() {
+			ctx.Export(key, value)
+		}
 
 		return nil
 	})
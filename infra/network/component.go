@@ -0,0 +1,124 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/core"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// VcnComponentType is the Pulumi type token of the Vcn component
+const VcnComponentType = "infra:network:Vcn"
+
+// Output keys of the Vcn component, also used as stack export keys for StackReference consumers
+const (
+	OutputVcnID           = "vcn_id"
+	OutputSubnets         = "subnets"
+	OutputSecurityListIDs = "security_list_ids"
+	OutputNSGIDs          = "nsg_ids"
+)
+
+// Vcn is a component resource that parents a VCN and every networking resource created in it
+type Vcn struct {
+	pulumi.ResourceState
+
+	Vcn           *core.Vcn
+	SecurityLists map[string]*core.SecurityList
+	Gateways      map[string]*Gateway
+	RouteTables   map[string]*core.RouteTable
+	DHCPOptions   map[string]*core.DhcpOptions
+	NSGs          map[string]*core.NetworkSecurityGroup
+	// Subnets holds the created subnets keyed by their config name
+	Subnets map[string]*core.Subnet
+}
+
+// NewVcn creates the VCN described by n with its security lists, gateways, route tables, DHCP options,
+// network security groups and subnets as children of a single component. Child resource names are
+// prefixed with the component name so that several VCNs can be created in one stack.
+func NewVcn(ctx *pulumi.Context, name string, n *NetCfg, opts ...pulumi.ResourceOption) (*Vcn, error) {
+	component := &Vcn{}
+	if err := ctx.RegisterComponentResource(VcnComponentType, name, component, opts...); err != nil {
+		return nil, err
+	}
+
+	n.namePrefix = name
+	n.resourceOptions = []pulumi.ResourceOption{pulumi.Parent(component)}
+
+	vcn, err := n.CreateVCN(ctx, "vcn")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create VCN: %w", err)
+	}
+	component.Vcn = vcn
+	vcnID := vcn.ID().ToStringOutput()
+
+	component.SecurityLists, err = n.CreateACLMap(ctx, vcnID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create security lists: %w", err)
+	}
+
+	component.Gateways, err = n.CreateGateways(ctx, vcnID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gateways: %w", err)
+	}
+
+	n.RouteTableMap, err = n.CreateRouteTables(ctx, vcnID, component.Gateways)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create route tables: %w", err)
+	}
+	component.RouteTables = n.RouteTableMap
+
+	n.DHCPOptionsMap, err = n.CreateDHCPOptions(ctx, vcnID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DHCP options: %w", err)
+	}
+	component.DHCPOptions = n.DHCPOptionsMap
+
+	component.NSGs, err = n.CreateNSGs(ctx, vcnID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create network security groups: %w", err)
+	}
+
+	subnets, err := n.CreateAllSubnetsWithSecurityLists(ctx, vcnID, component.SecurityLists)
+	if err != nil {
+		return nil, err
+	}
+	component.Subnets = make(map[string]*core.Subnet, len(subnets))
+	for i, subnet := range subnets {
+		component.Subnets[n.Subnets[i].Name] = subnet
+	}
+
+	if err := ctx.RegisterResourceOutputs(component, component.Outputs()); err != nil {
+		return nil, err
+	}
+
+	return component, nil
+}
+
+// Outputs returns the VCN ID, the subnets keyed by config name with their id and cidr, and the
+// security list and network security group IDs keyed by display name
+func (v *Vcn) Outputs() pulumi.Map {
+	subnets := pulumi.Map{}
+	for name, subnet := range v.Subnets {
+		subnets[name] = pulumi.Map{
+			"id":   subnet.ID().ToStringOutput(),
+			"cidr": subnet.CidrBlock,
+		}
+	}
+
+	securityListIDs := pulumi.StringMap{}
+	for name, seclist := range v.SecurityLists {
+		securityListIDs[name] = seclist.ID().ToStringOutput()
+	}
+
+	nsgIDs := pulumi.StringMap{}
+	for name, nsg := range v.NSGs {
+		nsgIDs[name] = nsg.ID().ToStringOutput()
+	}
+
+	return pulumi.Map{
+		OutputVcnID:           v.Vcn.ID().ToStringOutput(),
+		OutputSubnets:         subnets,
+		OutputSecurityListIDs: securityListIDs,
+		OutputNSGIDs:          nsgIDs,
+	}
+}
@@ -0,0 +1,161 @@
+package network
+
+import (
+	"fmt"
+	"infra/config"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// ComponentMocks records the type and parent URN of every registered resource by name
+type ComponentMocks struct {
+	GatewayMocks
+	mu      sync.Mutex
+	types   map[string]string
+	parents map[string]string
+}
+
+func newComponentMocks() *ComponentMocks {
+	return &ComponentMocks{
+		GatewayMocks: GatewayMocks{resources: make(map[string]resource.PropertyMap)},
+		types:        make(map[string]string),
+		parents:      make(map[string]string),
+	}
+}
+
+func (m *ComponentMocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	m.mu.Lock()
+	if _, ok := m.types[args.Name]; ok {
+		m.mu.Unlock()
+		return "", nil, fmt.Errorf("duplicate resource name %s", args.Name)
+	}
+	m.types[args.Name] = args.TypeToken
+	if args.RegisterRPC != nil {
+		m.parents[args.Name] = args.RegisterRPC.GetParent()
+	}
+	m.mu.Unlock()
+	return m.GatewayMocks.NewResource(args)
+}
+
+func newTestComponentCfg() NetCfg {
+	return NetCfg{
+		NetworkConfig: config.NetworkConfig{
+			BaseConfig:  config.BaseConfig{CompartmentID: "compartment-123"},
+			CidrBlock:   "10.0.0.0/16",
+			DisplayName: "test-vcn",
+			Subnets: []config.SubnetConfig{
+				{Name: "public-subnet", CidrBlock: "10.0.1.0/24", Public: true, RouteTable: "public-routes"},
+				{Name: "private-subnet", CidrBlock: "10.0.2.0/24"},
+			},
+			Gateways: []config.GatewayConfig{{Name: "internet-gateway", Type: GatewayInternet}},
+			RouteTables: []config.RouteTableConfig{
+				{Name: "public-routes", Rules: []config.RouteRuleConfig{{Destination: "0.0.0.0/0", Gateway: "internet-gateway"}}},
+			},
+			SecurityLists: []config.SecurityListConfig{
+				{
+					DisplayName:  "public-subnet-security-list",
+					SubnetName:   "public-subnet",
+					IngressRules: []config.SecurityRuleConfig{{Protocol: "tcp", Source: "0.0.0.0/0"}},
+				},
+			},
+			NetworkSecurityGroups: []config.NetworkSecurityGroupConfig{
+				{DisplayName: "app-nsg", Rules: []config.NSGRuleConfig{{Direction: "egress", Protocol: "all", Destination: "0.0.0.0/0"}}},
+			},
+		},
+	}
+}
+
+func TestNewVcn(t *testing.T) {
+	mocks := newComponentMocks()
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		for _, name := range []string{"dev", "prod"} {
+			netCfg := newTestComponentCfg()
+			vcn, err := NewVcn(ctx, name, &netCfg)
+			if err != nil {
+				return err
+			}
+			if len(vcn.Subnets) != 2 || vcn.Subnets["public-subnet"] == nil {
+				t.Errorf("Expected subnets keyed by config name, but got %v", vcn.Subnets)
+			}
+			if vcn.NSGs["app-nsg"] == nil || vcn.SecurityLists["public-subnet-security-list"] == nil {
+				t.Errorf("Expected NSGs and security lists keyed by display name")
+			}
+		}
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"dev", "prod"} {
+		if got := mocks.types[name]; got != VcnComponentType {
+			t.Errorf("Expected %s to be a %s component, but got %s", name, VcnComponentType, got)
+		}
+		for _, child := range []string{"vcn", "public-subnet", "private-subnet", "internet-gateway", "public-routes", "public-subnet-security-list", "app-nsg", "app-nsg-egress-0"} {
+			childName := name + "-" + child
+			if _, ok := mocks.types[childName]; !ok {
+				t.Errorf("Expected resource %s to be created", childName)
+				continue
+			}
+			if parent := mocks.parents[childName]; !strings.HasSuffix(parent, VcnComponentType+"::"+name) {
+				t.Errorf("Expected %s to be parented by the %s component, but got %q", childName, name, parent)
+			}
+		}
+	}
+
+	if display := mocks.resources["dev-public-subnet"]["displayName"].StringValue(); display != "public-subnet" {
+		t.Errorf("Expected display name to stay public-subnet, but got %s", display)
+	}
+}
+
+func TestVcnOutputs(t *testing.T) {
+	var wg sync.WaitGroup
+	var outputs map[string]interface{}
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		netCfg := newTestComponentCfg()
+		vcn, err := NewVcn(ctx, "dev", &netCfg)
+		if err != nil {
+			return err
+		}
+
+		wg.Add(1)
+		vcn.Outputs().ToMapOutput().ApplyT(func(m map[string]interface{}) error {
+			defer wg.Done()
+			outputs = m
+			return nil
+		})
+		return nil
+	}, pulumi.WithMocks("project", "stack", newComponentMocks()))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	wg.Wait()
+
+	if got := outputs[OutputVcnID]; got != "dev-vcn_id" {
+		t.Errorf("Expected vcn_id dev-vcn_id, but got %v", got)
+	}
+
+	subnets, ok := outputs[OutputSubnets].(map[string]interface{})
+	if !ok || len(subnets) != 2 {
+		t.Fatalf("Expected 2 subnets keyed by name, but got %v", outputs[OutputSubnets])
+	}
+	public := subnets["public-subnet"].(map[string]interface{})
+	if public["id"] != "dev-public-subnet_id" || public["cidr"] != "10.0.1.0/24" {
+		t.Errorf("Expected public-subnet id and cidr, but got %v", public)
+	}
+
+	seclists := outputs[OutputSecurityListIDs].(map[string]string)
+	if seclists["public-subnet-security-list"] != "dev-public-subnet-security-list_id" {
+		t.Errorf("Expected security list ID keyed by display name, but got %v", seclists)
+	}
+	nsgs := outputs[OutputNSGIDs].(map[string]string)
+	if nsgs["app-nsg"] != "dev-app-nsg_id" {
+		t.Errorf("Expected NSG ID keyed by display name, but got %v", nsgs)
+	}
+}
@@ -0,0 +1,71 @@
+package network
+
+import (
+	"fmt"
+	"infra/config"
+
+	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/core"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// DHCP DNS server types accepted in dhcp_options[].server_type
+const (
+	DNSServerVcnLocalPlusInternet = "VcnLocalPlusInternet"
+	DNSServerCustom               = "CustomDnsServer"
+)
+
+// CreateDHCPOptions creates the DHCP options of the VCN and returns a map of names to DHCP options resources
+func (n *NetCfg) CreateDHCPOptions(ctx *pulumi.Context, vcnID pulumi.StringInput) (map[string]*core.DhcpOptions, error) {
+	dhcpOptions := make(map[string]*core.DhcpOptions)
+
+	for _, v := range n.DHCPOptions {
+		serverType := v.ServerType
+		if serverType == "" {
+			serverType = DNSServerVcnLocalPlusInternet
+		}
+
+		dnsOption := &core.DhcpOptionsOptionArgs{
+			Type:       pulumi.String("DomainNameServer"),
+			ServerType: pulumi.String(serverType),
+		}
+		if serverType == DNSServerCustom {
+			dnsOption.CustomDnsServers = pulumi.ToStringArray(v.CustomDNSServers)
+		}
+
+		options := core.DhcpOptionsOptionArray{dnsOption}
+		if len(v.SearchDomains) > 0 {
+			options = append(options, &core.DhcpOptionsOptionArgs{
+				Type:              pulumi.String("SearchDomain"),
+				SearchDomainNames: pulumi.ToStringArray(v.SearchDomains),
+			})
+		}
+
+		opts, err := core.NewDhcpOptions(ctx, n.resourceName(v.Name), &core.DhcpOptionsArgs{
+			CompartmentId: n.compartmentID(),
+			VcnId:         vcnID,
+			DisplayName:   pulumi.String(v.Name),
+			Options:       options,
+		}, n.resourceOptions...)
+		if err != nil {
+			return nil, err
+		}
+		dhcpOptions[v.Name] = opts
+	}
+
+	return dhcpOptions, nil
+}
+
+// GetDHCPOptionsIDForSubnet returns the ID of the DHCP options configured for a subnet,
+// or nil to use the VCN default DHCP options
+func (n *NetCfg) GetDHCPOptionsIDForSubnet(subnet config.SubnetConfig) (pulumi.StringPtrInput, error) {
+	if subnet.DHCPOptions == "" {
+		return nil, nil
+	}
+
+	opts, ok := n.DHCPOptionsMap[subnet.DHCPOptions]
+	if !ok || opts == nil {
+		return nil, fmt.Errorf("dhcp options %s not found", subnet.DHCPOptions)
+	}
+
+	return opts.ID().ToStringOutput(), nil
+}
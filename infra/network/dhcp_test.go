@@ -0,0 +1,103 @@
+package network
+
+import (
+	"infra/config"
+	"testing"
+
+	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/core"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+func TestCreateSubnetsWithDHCPOptions(t *testing.T) {
+	netCfg := NetCfg{
+		NetworkConfig: config.NetworkConfig{
+			BaseConfig: config.BaseConfig{
+				CompartmentID: "compartment-123",
+			},
+			CidrBlock:   "10.0.0.0/16",
+			DisplayName: "test-vcn",
+			DNSLabel:    "testvcn",
+			Subnets: []config.SubnetConfig{
+				{Name: "public-subnet", CidrBlock: "10.0.1.0/24", Public: true, DNSLabel: "public"},
+				{Name: "database-subnet", CidrBlock: "10.0.3.0/24", AvailabilityDomain: "Uocm:EU-FRANKFURT-1-AD-1", DHCPOptions: "custom-dns"},
+			},
+			DHCPOptions: []config.DHCPOptionsConfig{
+				{Name: "custom-dns", ServerType: DNSServerCustom, CustomDNSServers: []string{"10.0.0.2"}, SearchDomains: []string{"example.internal"}},
+			},
+		},
+	}
+	mocks := &GatewayMocks{resources: make(map[string]resource.PropertyMap)}
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		vcn, err := netCfg.CreateVCN(ctx, netCfg.DisplayName)
+		if err != nil {
+			return err
+		}
+
+		netCfg.DHCPOptionsMap, err = netCfg.CreateDHCPOptions(ctx, vcn.ID())
+		if err != nil {
+			return err
+		}
+
+		_, err = netCfg.CreateAllSubnetsWithSecurityLists(ctx, vcn.ID(), map[string]*core.SecurityList{})
+		return err
+	}, pulumi.WithMocks("project", "stack", mocks))
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if label := mocks.resources["test-vcn"]["dnsLabel"].StringValue(); label != "testvcn" {
+		t.Errorf("Expected VCN dns label testvcn, but got %s", label)
+	}
+
+	public := mocks.resources["public-subnet"]
+	if public["prohibitPublicIpOnVnic"].BoolValue() {
+		t.Error("Expected public-subnet to allow public IPs")
+	}
+	if public["dnsLabel"].StringValue() != "public" {
+		t.Errorf("Expected public-subnet dns label public, but got %v", public["dnsLabel"])
+	}
+	if public.HasValue("availabilityDomain") {
+		t.Errorf("Expected public-subnet to be regional, but got %v", public["availabilityDomain"])
+	}
+
+	database := mocks.resources["database-subnet"]
+	if !database["prohibitPublicIpOnVnic"].BoolValue() {
+		t.Error("Expected database-subnet to prohibit public IPs")
+	}
+	if database["availabilityDomain"].StringValue() != "Uocm:EU-FRANKFURT-1-AD-1" {
+		t.Errorf("Expected database-subnet to be AD specific, but got %v", database["availabilityDomain"])
+	}
+	if database["dhcpOptionsId"].StringValue() != "custom-dns_id" {
+		t.Errorf("Expected database-subnet to use custom-dns, but got %v", database["dhcpOptionsId"])
+	}
+
+	options := mocks.resources["custom-dns"]["options"].ArrayValue()
+	if len(options) != 2 {
+		t.Fatalf("Expected DNS server and search domain options, but got %d", len(options))
+	}
+}
+
+func TestCreateSubnetWithUnknownDHCPOptions(t *testing.T) {
+	netCfg := NetCfg{
+		NetworkConfig: config.NetworkConfig{
+			BaseConfig: config.BaseConfig{
+				CompartmentID: "compartment-123",
+			},
+			Subnets: []config.SubnetConfig{
+				{Name: "private-subnet", CidrBlock: "10.0.2.0/24", DHCPOptions: "custom-dns"},
+			},
+		},
+	}
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		_, err := netCfg.CreateSubnet(ctx, 0, pulumi.String("vcn-123"), nil)
+		return err
+	}, pulumi.WithMocks("project", "stack", SubnetMocks(0)))
+
+	if err == nil {
+		t.Error("Expected error for subnet referencing dhcp options that were not created, but got none")
+	}
+}
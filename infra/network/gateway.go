@@ -0,0 +1,177 @@
+package network
+
+import (
+	"fmt"
+	"infra/config"
+	"strings"
+
+	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/core"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// Gateway types accepted in gateways[].type
+const (
+	GatewayInternet = "internet"
+	GatewayNAT      = "nat"
+	GatewayService  = "service"
+)
+
+// OCI services a service gateway can route to, accepted in gateways[].services
+const (
+	ServicesAll           = "all"
+	ServicesObjectStorage = "objectstorage"
+)
+
+// Gateway is a created gateway of any type, as referenced by route rules
+type Gateway struct {
+	Type string
+	ID   pulumi.IDOutput
+	// ServiceCidr is the OCI service label a service gateway routes to, e.g. all-fra-services-in-oracle-services-network
+	ServiceCidr string
+}
+
+// CreateGateways creates the internet, nat and service gateways of the VCN and returns them keyed by name
+func (n *NetCfg) CreateGateways(ctx *pulumi.Context, vcnID pulumi.StringInput) (map[string]*Gateway, error) {
+	gateways := make(map[string]*Gateway)
+
+	for _, v := range n.Gateways {
+		var gateway *Gateway
+		var err error
+
+		switch v.Type {
+		case GatewayInternet:
+			var igw *core.InternetGateway
+			igw, err = core.NewInternetGateway(ctx, n.resourceName(v.Name), &core.InternetGatewayArgs{
+				CompartmentId: n.compartmentID(),
+				VcnId:         vcnID,
+				DisplayName:   pulumi.String(v.Name),
+				Enabled:       pulumi.Bool(true),
+			}, n.resourceOptions...)
+			if err == nil {
+				gateway = &Gateway{Type: v.Type, ID: igw.ID()}
+			}
+		case GatewayNAT:
+			var nat *core.NatGateway
+			nat, err = core.NewNatGateway(ctx, n.resourceName(v.Name), &core.NatGatewayArgs{
+				CompartmentId: n.compartmentID(),
+				VcnId:         vcnID,
+				DisplayName:   pulumi.String(v.Name),
+			}, n.resourceOptions...)
+			if err == nil {
+				gateway = &Gateway{Type: v.Type, ID: nat.ID()}
+			}
+		case GatewayService:
+			gateway, err = n.createServiceGateway(ctx, vcnID, v.Name, v.Services)
+		default:
+			err = fmt.Errorf("unknown gateway type %q", v.Type)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gateway %s: %w", v.Name, err)
+		}
+		gateways[v.Name] = gateway
+	}
+
+	return gateways, nil
+}
+
+// createServiceGateway looks up the requested OCI services of the region and creates a service gateway for them
+func (n *NetCfg) createServiceGateway(ctx *pulumi.Context, vcnID pulumi.StringInput, name, services string) (*Gateway, error) {
+	if services == "" {
+		services = ServicesAll
+	}
+
+	result, err := core.GetServices(ctx, &core.GetServicesArgs{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, svc := range result.Services {
+		if !matchesServices(svc.CidrBlock, services) {
+			continue
+		}
+
+		sgw, err := core.NewServiceGateway(ctx, n.resourceName(name), &core.ServiceGatewayArgs{
+			CompartmentId: n.compartmentID(),
+			VcnId:         vcnID,
+			DisplayName:   pulumi.String(name),
+			Services: core.ServiceGatewayServiceArray{
+				&core.ServiceGatewayServiceArgs{ServiceId: pulumi.String(svc.Id)},
+			},
+		}, n.resourceOptions...)
+		if err != nil {
+			return nil, err
+		}
+		return &Gateway{Type: GatewayService, ID: sgw.ID(), ServiceCidr: svc.CidrBlock}, nil
+	}
+
+	return nil, fmt.Errorf("no OCI service matching %q found in this region", services)
+}
+
+// matchesServices reports whether an OCI service cidr label belongs to the requested services
+func matchesServices(cidrLabel, services string) bool {
+	switch services {
+	case ServicesAll:
+		return strings.HasPrefix(cidrLabel, "all-") && strings.HasSuffix(cidrLabel, "-services-in-oracle-services-network")
+	case ServicesObjectStorage:
+		return strings.HasPrefix(cidrLabel, "oci-") && strings.HasSuffix(cidrLabel, "-objectstorage")
+	default:
+		return false
+	}
+}
+
+// CreateRouteTables creates the route tables of the VCN with rules pointing at the given gateways
+// and returns a map of route table names to route table resources
+func (n *NetCfg) CreateRouteTables(ctx *pulumi.Context, vcnID pulumi.StringInput, gateways map[string]*Gateway) (map[string]*core.RouteTable, error) {
+	routeTables := make(map[string]*core.RouteTable)
+
+	for _, v := range n.RouteTables {
+		var rules core.RouteTableRouteRuleArray
+		for i, rule := range v.Rules {
+			gateway, ok := gateways[rule.Gateway]
+			if !ok {
+				return nil, fmt.Errorf("route table %s rule %d: gateway %s not found", v.Name, i, rule.Gateway)
+			}
+
+			routeRule := &core.RouteTableRouteRuleArgs{
+				NetworkEntityId: gateway.ID,
+				Destination:     pulumi.String(rule.Destination),
+				DestinationType: pulumi.String("CIDR_BLOCK"),
+				Description:     pulumi.String(rule.Description),
+			}
+			if gateway.Type == GatewayService {
+				routeRule.Destination = pulumi.String(gateway.ServiceCidr)
+				routeRule.DestinationType = pulumi.String("SERVICE_CIDR_BLOCK")
+			}
+			rules = append(rules, routeRule)
+		}
+
+		rt, err := core.NewRouteTable(ctx, n.resourceName(v.Name), &core.RouteTableArgs{
+			CompartmentId: n.compartmentID(),
+			VcnId:         vcnID,
+			DisplayName:   pulumi.String(v.Name),
+			RouteRules:    rules,
+		}, n.resourceOptions...)
+		if err != nil {
+			return nil, err
+		}
+		routeTables[v.Name] = rt
+	}
+
+	return routeTables, nil
+}
+
+// GetRouteTableIDForSubnet returns the ID of the route table configured for a subnet,
+// or nil to use the VCN default route table
+func (n *NetCfg) GetRouteTableIDForSubnet(subnet config.SubnetConfig) (pulumi.StringPtrInput, error) {
+	if subnet.RouteTable == "" {
+		return nil, nil
+	}
+
+	rt, ok := n.RouteTableMap[subnet.RouteTable]
+	if !ok || rt == nil {
+		return nil, fmt.Errorf("route table %s not found", subnet.RouteTable)
+	}
+
+	return rt.ID().ToStringOutput(), nil
+}
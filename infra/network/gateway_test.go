@@ -0,0 +1,181 @@
+package network
+
+import (
+	"infra/config"
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/core"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// GatewayMocks answers the OCI services lookup and records the inputs of created resources by name
+type GatewayMocks struct {
+	mu        sync.Mutex
+	resources map[string]resource.PropertyMap
+}
+
+func (m *GatewayMocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	m.mu.Lock()
+	m.resources[args.Name] = args.Inputs
+	m.mu.Unlock()
+	return args.Name + "_id", args.Inputs, nil
+}
+
+func (m *GatewayMocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+	if args.Token == "oci:Core/getServices:getServices" {
+		return resource.NewPropertyMapFromMap(map[string]interface{}{
+			"id": "services",
+			"services": []interface{}{
+				map[string]interface{}{
+					"id":        "ocid1.service.oc1..objectstorage",
+					"cidrBlock": "oci-fra-objectstorage",
+					"name":      "OCI FRA Object Storage",
+				},
+				map[string]interface{}{
+					"id":        "ocid1.service.oc1..all",
+					"cidrBlock": "all-fra-services-in-oracle-services-network",
+					"name":      "All FRA Services In Oracle Services Network",
+				},
+			},
+		}), nil
+	}
+	return args.Args, nil
+}
+
+func newTestGatewayCfg() NetCfg {
+	return NetCfg{
+		NetworkConfig: config.NetworkConfig{
+			BaseConfig: config.BaseConfig{
+				CompartmentID: "compartment-123",
+			},
+			CidrBlock:   "10.0.0.0/16",
+			DisplayName: "test-vcn",
+			Subnets: []config.SubnetConfig{
+				{Name: "public-subnet", CidrBlock: "10.0.1.0/24", RouteTable: "public-routes"},
+				{Name: "private-subnet", CidrBlock: "10.0.2.0/24", RouteTable: "private-routes"},
+			},
+			Gateways: []config.GatewayConfig{
+				{Name: "internet-gateway", Type: "internet"},
+				{Name: "nat-gateway", Type: "nat"},
+				{Name: "service-gateway", Type: "service"},
+			},
+			RouteTables: []config.RouteTableConfig{
+				{
+					Name: "public-routes",
+					Rules: []config.RouteRuleConfig{
+						{Destination: "0.0.0.0/0", Gateway: "internet-gateway"},
+					},
+				},
+				{
+					Name: "private-routes",
+					Rules: []config.RouteRuleConfig{
+						{Destination: "0.0.0.0/0", Gateway: "nat-gateway"},
+						{Gateway: "service-gateway"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestCreateGatewaysAndRouteTables(t *testing.T) {
+	netCfg := newTestGatewayCfg()
+	mocks := &GatewayMocks{resources: make(map[string]resource.PropertyMap)}
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		gateways, err := netCfg.CreateGateways(ctx, pulumi.String("vcn-123"))
+		if err != nil {
+			return err
+		}
+		if len(gateways) != 3 {
+			t.Errorf("Expected 3 gateways, but got %d", len(gateways))
+		}
+		if cidr := gateways["service-gateway"].ServiceCidr; cidr != "all-fra-services-in-oracle-services-network" {
+			t.Errorf("Expected service gateway to route to all services, but got %s", cidr)
+		}
+
+		routeTables, err := netCfg.CreateRouteTables(ctx, pulumi.String("vcn-123"), gateways)
+		if err != nil {
+			return err
+		}
+		if len(routeTables) != 2 {
+			t.Errorf("Expected 2 route tables, but got %d", len(routeTables))
+		}
+
+		netCfg.RouteTableMap = routeTables
+		_, err = netCfg.CreateAllSubnetsWithSecurityLists(ctx, pulumi.String("vcn-123"), map[string]*core.SecurityList{})
+		return err
+	}, pulumi.WithMocks("project", "stack", mocks))
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	rules := mocks.resources["private-routes"]["routeRules"].ArrayValue()
+	if len(rules) != 2 {
+		t.Fatalf("Expected 2 private route rules, but got %d", len(rules))
+	}
+	serviceRule := rules[1].ObjectValue()
+	if serviceRule["destinationType"].StringValue() != "SERVICE_CIDR_BLOCK" {
+		t.Errorf("Expected service gateway rule to use SERVICE_CIDR_BLOCK, but got %v", serviceRule["destinationType"])
+	}
+	if serviceRule["networkEntityId"].StringValue() != "service-gateway_id" {
+		t.Errorf("Expected service gateway rule to target service-gateway, but got %v", serviceRule["networkEntityId"])
+	}
+
+	if rt := mocks.resources["private-subnet"]["routeTableId"].StringValue(); rt != "private-routes_id" {
+		t.Errorf("Expected private-subnet to use private-routes, but got %s", rt)
+	}
+}
+
+func TestCreateRouteTablesUnknownGateway(t *testing.T) {
+	netCfg := newTestGatewayCfg()
+	mocks := &GatewayMocks{resources: make(map[string]resource.PropertyMap)}
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		_, err := netCfg.CreateRouteTables(ctx, pulumi.String("vcn-123"), map[string]*Gateway{})
+		return err
+	}, pulumi.WithMocks("project", "stack", mocks))
+
+	if err == nil {
+		t.Error("Expected error for route rule referencing a missing gateway, but got none")
+	}
+}
+
+func TestCreateSubnetWithUnknownRouteTable(t *testing.T) {
+	netCfg := newTestGatewayCfg()
+	mocks := &GatewayMocks{resources: make(map[string]resource.PropertyMap)}
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		_, err := netCfg.CreateSubnetWithSecurityLists(ctx, 0, pulumi.String("vcn-123"), map[string]*core.SecurityList{})
+		return err
+	}, pulumi.WithMocks("project", "stack", mocks))
+
+	if err == nil {
+		t.Error("Expected error for subnet referencing a route table that was not created, but got none")
+	}
+}
+
+func TestMatchesServices(t *testing.T) {
+	tests := []struct {
+		cidrLabel string
+		services  string
+		expected  bool
+	}{
+		{"all-fra-services-in-oracle-services-network", ServicesAll, true},
+		{"oci-fra-objectstorage", ServicesAll, false},
+		{"oci-fra-objectstorage", ServicesObjectStorage, true},
+		{"all-fra-services-in-oracle-services-network", ServicesObjectStorage, false},
+		{"oci-fra-objectstorage", "streaming", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cidrLabel+"/"+tt.services, func(t *testing.T) {
+			if got := matchesServices(tt.cidrLabel, tt.services); got != tt.expected {
+				t.Errorf("Expected %v, but got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package network
+
+import (
+	"fmt"
+	"infra/config"
+	"infra/network/rules"
+	"net/netip"
+	"strings"
+
+	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/core"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+const (
+	nsgTypeCIDR = "CIDR_BLOCK"
+	nsgTypeNSG  = "NETWORK_SECURITY_GROUP"
+)
+
+// CreateNSGs creates the network security groups and their rules and returns a map of display names
+// to network security group resources. All groups are created before any rule so that rules can
+// reference other groups by display name.
+func (n *NetCfg) CreateNSGs(ctx *pulumi.Context, vcnID pulumi.StringInput) (map[string]*core.NetworkSecurityGroup, error) {
+	nsgMap := make(map[string]*core.NetworkSecurityGroup)
+
+	for _, v := range n.NetworkSecurityGroups {
+		nsg, err := core.NewNetworkSecurityGroup(ctx, n.resourceName(v.DisplayName), &core.NetworkSecurityGroupArgs{
+			CompartmentId: n.compartmentID(),
+			VcnId:         vcnID,
+			DisplayName:   pulumi.String(v.DisplayName),
+		}, n.resourceOptions...)
+		if err != nil {
+			return nil, err
+		}
+		nsgMap[v.DisplayName] = nsg
+	}
+
+	for _, v := range n.NetworkSecurityGroups {
+		for i, rule := range v.Rules {
+			if err := n.createNSGRules(ctx, nsgMap, v.DisplayName, i, rule); err != nil {
+				return nil, fmt.Errorf("network security group %s rule %d: %w", v.DisplayName, i, err)
+			}
+		}
+	}
+
+	return nsgMap, nil
+}
+
+// createNSGRules creates the OCI rules for a single rule config, one per tcp or udp port range
+func (n *NetCfg) createNSGRules(ctx *pulumi.Context, nsgMap map[string]*core.NetworkSecurityGroup, nsgName string, index int, rule config.NSGRuleConfig) error {
+	compiled, err := rules.CompileNSGRule(rule)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s-%d", nsgName, strings.ToLower(rule.Direction), index)
+	var tcpIndex, udpIndex int
+	for _, r := range compiled {
+		args := core.NetworkSecurityGroupSecurityRuleArgs{
+			NetworkSecurityGroupId: nsgMap[nsgName].ID(),
+			Direction:              pulumi.String(r.Direction),
+			Protocol:               pulumi.String(r.Protocol),
+			Description:            pulumi.String(r.Description),
+			Stateless:              pulumi.Bool(r.Stateless),
+		}
+
+		endpoint, endpointType, err := nsgEndpoint(nsgMap, r.Endpoint)
+		if r.Direction == rules.Ingress {
+			if err != nil {
+				return fmt.Errorf("source: %w", err)
+			}
+			args.Source, args.SourceType = endpoint, endpointType
+		} else {
+			if err != nil {
+				return fmt.Errorf("destination: %w", err)
+			}
+			args.Destination, args.DestinationType = endpoint, endpointType
+		}
+
+		ruleName := name
+		switch {
+		case r.TCP != nil:
+			args.TcpOptions = nsgTCPOptions(r.TCP)
+			ruleName = fmt.Sprintf("%s-%d", name, tcpIndex)
+			tcpIndex++
+		case r.UDP != nil:
+			args.UdpOptions = nsgUDPOptions(r.UDP)
+			ruleName = fmt.Sprintf("%s-udp-%d", name, udpIndex)
+			udpIndex++
+		}
+		if r.ICMP != nil {
+			args.IcmpOptions = &core.NetworkSecurityGroupSecurityRuleIcmpOptionsArgs{
+				Type: pulumi.Int(r.ICMP.Type),
+				Code: pulumi.IntPtrFromPtr(r.ICMP.Code),
+			}
+		}
+
+		if _, err := core.NewNetworkSecurityGroupSecurityRule(ctx, n.resourceName(ruleName), &args, n.resourceOptions...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nsgTCPOptions converts compiled tcp port options into NSG rule arguments
+func nsgTCPOptions(opts *rules.PortOptions) *core.NetworkSecurityGroupSecurityRuleTcpOptionsArgs {
+	tcp := &core.NetworkSecurityGroupSecurityRuleTcpOptionsArgs{}
+	if r := opts.Destination; r != nil {
+		tcp.DestinationPortRange = &core.NetworkSecurityGroupSecurityRuleTcpOptionsDestinationPortRangeArgs{Min: pulumi.Int(r.Min), Max: pulumi.Int(r.Max)}
+	}
+	if r := opts.Source; r != nil {
+		tcp.SourcePortRange = &core.NetworkSecurityGroupSecurityRuleTcpOptionsSourcePortRangeArgs{Min: pulumi.Int(r.Min), Max: pulumi.Int(r.Max)}
+	}
+	return tcp
+}
+
+// nsgUDPOptions converts compiled udp port options into NSG rule arguments
+func nsgUDPOptions(opts *rules.PortOptions) *core.NetworkSecurityGroupSecurityRuleUdpOptionsArgs {
+	udp := &core.NetworkSecurityGroupSecurityRuleUdpOptionsArgs{}
+	if r := opts.Destination; r != nil {
+		udp.DestinationPortRange = &core.NetworkSecurityGroupSecurityRuleUdpOptionsDestinationPortRangeArgs{Min: pulumi.Int(r.Min), Max: pulumi.Int(r.Max)}
+	}
+	if r := opts.Source; r != nil {
+		udp.SourcePortRange = &core.NetworkSecurityGroupSecurityRuleUdpOptionsSourcePortRangeArgs{Min: pulumi.Int(r.Min), Max: pulumi.Int(r.Max)}
+	}
+	return udp
+}
+
+// nsgEndpoint resolves a rule source or destination to either a CIDR block or the ID of a network security group
+func nsgEndpoint(nsgMap map[string]*core.NetworkSecurityGroup, value string) (pulumi.StringPtrInput, pulumi.StringPtrInput, error) {
+	if _, err := netip.ParsePrefix(value); err == nil {
+		return pulumi.String(value), pulumi.String(nsgTypeCIDR), nil
+	}
+
+	nsg, ok := nsgMap[value]
+	if !ok {
+		return nil, nil, fmt.Errorf("%q is neither a CIDR block nor a network security group", value)
+	}
+
+	return nsg.ID().ToStringOutput(), pulumi.String(nsgTypeNSG), nil
+}
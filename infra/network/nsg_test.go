@@ -0,0 +1,194 @@
+package network
+
+import (
+	"infra/config"
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// NSGMocks records the inputs of every security rule it creates
+type NSGMocks struct {
+	mu    sync.Mutex
+	rules map[string]resource.PropertyMap
+}
+
+func (m *NSGMocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	if args.TypeToken == "oci:Core/networkSecurityGroupSecurityRule:NetworkSecurityGroupSecurityRule" {
+		m.mu.Lock()
+		m.rules[args.Name] = args.Inputs
+		m.mu.Unlock()
+	}
+	return args.Name + "_id", args.Inputs, nil
+}
+
+func (m *NSGMocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+	return args.Args, nil
+}
+
+func newTestNSGCfg(rules []config.NSGRuleConfig) NetCfg {
+	return NetCfg{
+		NetworkConfig: config.NetworkConfig{
+			BaseConfig: config.BaseConfig{
+				CompartmentID: "compartment-123",
+			},
+			CidrBlock:   "10.0.0.0/16",
+			DisplayName: "test-vcn",
+			NetworkSecurityGroups: []config.NetworkSecurityGroupConfig{
+				{DisplayName: "app-nsg"},
+				{DisplayName: "db-nsg", Rules: rules},
+			},
+		},
+	}
+}
+
+func TestCreateNSGs(t *testing.T) {
+	netCfg := newTestNSGCfg([]config.NSGRuleConfig{
+		{
+			Direction:   "ingress",
+			Protocol:    "6",
+			Description: "Allow database access from the app tier",
+			Source:      "app-nsg",
+			TCPOptions: []config.TCPOptionConfig{
+				{MinPort: 1521, MaxPort: 1521},
+				{MinPort: 3306, MaxPort: 3306},
+			},
+		},
+		{
+			Direction:   "egress",
+			Protocol:    "all",
+			Description: "Allow all outbound traffic",
+			Destination: "0.0.0.0/0",
+		},
+	})
+	mocks := &NSGMocks{rules: make(map[string]resource.PropertyMap)}
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		nsgMap, err := netCfg.CreateNSGs(ctx, pulumi.String("vcn-123"))
+		if err != nil {
+			return err
+		}
+
+		for _, name := range []string{"app-nsg", "db-nsg"} {
+			if nsgMap[name] == nil {
+				t.Errorf("Expected network security group %s to be in map, but it was not found", name)
+			}
+		}
+
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// One rule per tcp port range plus the egress rule
+	expected := map[string]string{
+		"db-nsg-ingress-0-0": "NETWORK_SECURITY_GROUP",
+		"db-nsg-ingress-0-1": "NETWORK_SECURITY_GROUP",
+		"db-nsg-egress-1":    "CIDR_BLOCK",
+	}
+	if len(mocks.rules) != len(expected) {
+		t.Fatalf("Expected %d security rules, but got %d", len(expected), len(mocks.rules))
+	}
+	for name, endpointType := range expected {
+		inputs, ok := mocks.rules[name]
+		if !ok {
+			t.Errorf("Expected security rule %s to be created", name)
+			continue
+		}
+		key := resource.PropertyKey("sourceType")
+		if inputs["direction"].StringValue() == "EGRESS" {
+			key = "destinationType"
+		}
+		if got := inputs[key].StringValue(); got != endpointType {
+			t.Errorf("Expected %s %s to be %s, but got %s", name, key, endpointType, got)
+		}
+	}
+	if src := mocks.rules["db-nsg-ingress-0-0"]["source"].StringValue(); src != "app-nsg_id" {
+		t.Errorf("Expected ingress source to be the app-nsg ID, but got %s", src)
+	}
+}
+
+func TestCreateNSGsUDPAndICMP(t *testing.T) {
+	netCfg := newTestNSGCfg([]config.NSGRuleConfig{
+		{
+			Direction:  "ingress",
+			Protocol:   "udp",
+			Source:     "10.0.0.0/16",
+			UDPOptions: []config.UDPOptionConfig{{MinPort: 53, MaxPort: 53}, {SourceMinPort: 123, SourceMaxPort: 123}},
+		},
+		{
+			Direction:   "ingress",
+			Protocol:    "icmp",
+			Source:      "0.0.0.0/0",
+			ICMPOptions: &config.ICMPOptionConfig{Type: 3},
+		},
+	})
+	mocks := &NSGMocks{rules: make(map[string]resource.PropertyMap)}
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		_, err := netCfg.CreateNSGs(ctx, pulumi.String("vcn-123"))
+		return err
+	}, pulumi.WithMocks("project", "stack", mocks))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"db-nsg-ingress-0-udp-0", "db-nsg-ingress-0-udp-1"} {
+		inputs, ok := mocks.rules[name]
+		if !ok {
+			t.Fatalf("Expected rule %s to be created, but got %v", name, mocks.rules)
+		}
+		if got := inputs["protocol"].StringValue(); got != "17" {
+			t.Errorf("Expected %s protocol to be 17, but got %s", name, got)
+		}
+	}
+	udp := mocks.rules["db-nsg-ingress-0-udp-1"]["udpOptions"].ObjectValue()
+	if _, ok := udp["destinationPortRange"]; ok {
+		t.Errorf("Expected no destination port range, but got %v", udp["destinationPortRange"])
+	}
+	if _, ok := udp["sourcePortRange"]; !ok {
+		t.Errorf("Expected a source port range, but got %v", udp)
+	}
+
+	icmp, ok := mocks.rules["db-nsg-ingress-1"]
+	if !ok {
+		t.Fatalf("Expected icmp rule to be created, but got %v", mocks.rules)
+	}
+	if got := icmp["protocol"].StringValue(); got != "1" {
+		t.Errorf("Expected icmp protocol to be 1, but got %s", got)
+	}
+	if got := icmp["icmpOptions"].ObjectValue()["type"].NumberValue(); got != 3 {
+		t.Errorf("Expected icmp type 3, but got %v", got)
+	}
+}
+
+func TestCreateNSGsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule config.NSGRuleConfig
+	}{
+		{"Unknown source group", config.NSGRuleConfig{Direction: "ingress", Protocol: "6", Source: "web-nsg"}},
+		{"Invalid direction", config.NSGRuleConfig{Direction: "sideways", Protocol: "6", Source: "0.0.0.0/0"}},
+		{"Invalid protocol", config.NSGRuleConfig{Direction: "ingress", Protocol: "sctp", Source: "0.0.0.0/0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			netCfg := newTestNSGCfg([]config.NSGRuleConfig{tt.rule})
+			mocks := &NSGMocks{rules: make(map[string]resource.PropertyMap)}
+
+			err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+				_, err := netCfg.CreateNSGs(ctx, pulumi.String("vcn-123"))
+				return err
+			}, pulumi.WithMocks("project", "stack", mocks))
+
+			if err == nil {
+				t.Errorf("Expected error but got none")
+			}
+		})
+	}
+}
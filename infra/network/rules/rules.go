@@ -0,0 +1,199 @@
+// Package rules compiles security rule configuration into flat OCI rules without depending on Pulumi,
+// so that security lists, network security groups and policy checks share the same expansion.
+package rules
+
+import (
+	"fmt"
+	"infra/config"
+	"strings"
+)
+
+// Rule directions as expected by OCI
+const (
+	Ingress = "INGRESS"
+	Egress  = "EGRESS"
+)
+
+// PortRange is an inclusive port range
+type PortRange struct {
+	Min int
+	Max int
+}
+
+// PortOptions restricts a tcp or udp rule to destination and source port ranges. A nil range allows all ports.
+type PortOptions struct {
+	Destination *PortRange
+	Source      *PortRange
+}
+
+// ICMPOptions restricts an ICMP rule to a message type and optionally a code
+type ICMPOptions struct {
+	Type int
+	Code *int
+}
+
+// Rule is a single OCI security rule: one direction, one source or destination and at most one
+// tcp or udp port range
+type Rule struct {
+	Direction   string
+	Protocol    string
+	Endpoint    string
+	Description string
+	Stateless   bool
+	TCP         *PortOptions
+	UDP         *PortOptions
+	ICMP        *ICMPOptions
+}
+
+// Spec is the protocol independent input of the compiler, shared by security lists and NSG rules
+type Spec struct {
+	Direction   string
+	Protocol    string
+	Endpoints   []string
+	Description string
+	Stateless   bool
+	TCPOptions  []config.TCPOptionConfig
+	UDPOptions  []config.UDPOptionConfig
+	ICMPOptions *config.ICMPOptionConfig
+}
+
+// Compile expands a spec into one rule per endpoint and tcp or udp port range, translating
+// symbolic protocol names into OCI protocol numbers
+func Compile(spec Spec) ([]Rule, error) {
+	direction := strings.ToUpper(spec.Direction)
+	if direction != Ingress && direction != Egress {
+		return nil, fmt.Errorf("direction %q must be ingress or egress", spec.Direction)
+	}
+
+	protocol, err := config.ProtocolNumber(spec.Protocol)
+	if err != nil {
+		return nil, err
+	}
+
+	var icmp *ICMPOptions
+	if spec.ICMPOptions != nil {
+		icmp = &ICMPOptions{Type: spec.ICMPOptions.Type, Code: spec.ICMPOptions.Code}
+	}
+
+	var rules []Rule
+	for _, endpoint := range spec.Endpoints {
+		base := Rule{
+			Direction:   direction,
+			Protocol:    protocol,
+			Endpoint:    endpoint,
+			Description: spec.Description,
+			Stateless:   spec.Stateless,
+			ICMP:        icmp,
+		}
+
+		if len(spec.TCPOptions) == 0 && len(spec.UDPOptions) == 0 {
+			rules = append(rules, base)
+			continue
+		}
+		for _, opt := range spec.TCPOptions {
+			rule := base
+			rule.TCP = portOptions(opt)
+			rules = append(rules, rule)
+		}
+		for _, opt := range spec.UDPOptions {
+			rule := base
+			rule.UDP = portOptions(opt)
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, nil
+}
+
+// CompileSecurityList compiles the egress rules followed by the ingress rules of a security list
+// in either the grouped or the flat format
+func CompileSecurityList(seclist config.SecurityListConfig) ([]Rule, error) {
+	ingress, egress := seclist.Rules()
+
+	var specs []Spec
+	for _, rule := range egress {
+		specs = append(specs, securityRuleSpec(rule, Egress, rule.DestinationCIDRs()))
+	}
+	for _, rule := range ingress {
+		specs = append(specs, securityRuleSpec(rule, Ingress, rule.SourceCIDRs()))
+	}
+
+	var rules []Rule
+	for _, spec := range specs {
+		compiled, err := Compile(spec)
+		if err != nil {
+			return nil, fmt.Errorf("security list %s: %w", seclist.DisplayName, err)
+		}
+		rules = append(rules, compiled...)
+	}
+	return rules, nil
+}
+
+// CompileSecurityLists compiles every security list and returns the rules keyed by display name
+func CompileSecurityLists(seclists []config.SecurityListConfig) (map[string][]Rule, error) {
+	compiled := make(map[string][]Rule, len(seclists))
+	for _, seclist := range seclists {
+		rules, err := CompileSecurityList(seclist)
+		if err != nil {
+			return nil, err
+		}
+		compiled[seclist.DisplayName] = rules
+	}
+	return compiled, nil
+}
+
+// CompileNSGRule compiles a network security group rule. The endpoint is left unresolved and may
+// be either a CIDR block or the display name of another network security group.
+func CompileNSGRule(rule config.NSGRuleConfig) ([]Rule, error) {
+	endpoint := rule.Source
+	if strings.EqualFold(rule.Direction, Egress) {
+		endpoint = rule.Destination
+	}
+
+	return Compile(Spec{
+		Direction:   rule.Direction,
+		Protocol:    rule.Protocol,
+		Endpoints:   []string{endpoint},
+		Description: rule.Description,
+		Stateless:   rule.Stateless,
+		TCPOptions:  rule.TCPOptions,
+		UDPOptions:  rule.UDPOptions,
+		ICMPOptions: rule.ICMPOptions,
+	})
+}
+
+// Filter returns the rules with the given direction
+func Filter(rules []Rule, direction string) []Rule {
+	var filtered []Rule
+	for _, rule := range rules {
+		if rule.Direction == direction {
+			filtered = append(filtered, rule)
+		}
+	}
+	return filtered
+}
+
+func securityRuleSpec(rule config.SecurityRuleConfig, direction string, endpoints []string) Spec {
+	return Spec{
+		Direction:   direction,
+		Protocol:    rule.Protocol,
+		Endpoints:   endpoints,
+		Description: rule.Description,
+		Stateless:   rule.Stateless,
+		TCPOptions:  rule.TCPOptions,
+		UDPOptions:  rule.UDPOptions,
+		ICMPOptions: rule.ICMPOptions,
+	}
+}
+
+// portOptions converts a tcp or udp option, leaving unset ranges nil
+func portOptions(opt config.TCPOptionConfig) *PortOptions {
+	options := &PortOptions{}
+	if opt.MinPort != 0 || opt.MaxPort != 0 {
+		options.Destination = &PortRange{Min: opt.MinPort, Max: opt.MaxPort}
+	}
+	if opt.SourceMinPort != 0 || opt.SourceMaxPort != 0 {
+		options.Source = &PortRange{Min: opt.SourceMinPort, Max: opt.SourceMaxPort}
+	}
+	return options
+}
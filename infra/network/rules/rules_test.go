@@ -0,0 +1,231 @@
+package rules
+
+import (
+	"infra/config"
+	"testing"
+)
+
+func TestCompileSecurityList(t *testing.T) {
+	tests := []struct {
+		name            string
+		seclist         config.SecurityListConfig
+		expectedIngress int
+		expectedEgress  int
+		expectedPorts   []int
+		expectedError   bool
+	}{
+		{
+			name: "One rule per tcp port range",
+			seclist: config.SecurityListConfig{
+				Protocol: "tcp",
+				Source:   "0.0.0.0/0",
+				TCPOptions: []config.TCPOptionConfig{
+					{MinPort: 22, MaxPort: 22},
+					{MinPort: 80, MaxPort: 80},
+					{MinPort: 443, MaxPort: 443},
+				},
+			},
+			expectedIngress: 3,
+			expectedPorts:   []int{22, 80, 443},
+		},
+		{
+			name: "One rule per source and port range",
+			seclist: config.SecurityListConfig{
+				Protocol: "tcp",
+				Source:   "10.0.0.0/16",
+				Sources:  []string{"192.168.0.0/24"},
+				TCPOptions: []config.TCPOptionConfig{
+					{MinPort: 22, MaxPort: 22},
+					{MinPort: 443, MaxPort: 443},
+				},
+			},
+			expectedIngress: 4,
+			expectedPorts:   []int{22, 443, 22, 443},
+		},
+		{
+			name: "Ingress and egress without port ranges",
+			seclist: config.SecurityListConfig{
+				Protocol:     "all",
+				Sources:      []string{"10.0.0.0/16", "10.1.0.0/16"},
+				Destinations: []string{"0.0.0.0/0"},
+			},
+			expectedIngress: 2,
+			expectedEgress:  1,
+		},
+		{
+			name: "One rule per udp port range",
+			seclist: config.SecurityListConfig{
+				Protocol:    "udp",
+				Destination: "10.0.0.0/16",
+				UDPOptions: []config.UDPOptionConfig{
+					{MinPort: 53, MaxPort: 53},
+					{MinPort: 51820, MaxPort: 51820},
+				},
+			},
+			expectedEgress: 2,
+		},
+		{
+			name:          "Unknown protocol",
+			seclist:       config.SecurityListConfig{Protocol: "sctp", Source: "0.0.0.0/0"},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compiled, err := CompileSecurityList(tt.seclist)
+			if tt.expectedError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			ingress := Filter(compiled, Ingress)
+			egress := Filter(compiled, Egress)
+			if len(ingress) != tt.expectedIngress {
+				t.Errorf("Expected %d ingress rules, but got %d", tt.expectedIngress, len(ingress))
+			}
+			if len(egress) != tt.expectedEgress {
+				t.Errorf("Expected %d egress rules, but got %d", tt.expectedEgress, len(egress))
+			}
+			for i, port := range tt.expectedPorts {
+				if i >= len(ingress) || ingress[i].TCP == nil || ingress[i].TCP.Destination.Min != port {
+					t.Errorf("Expected ingress rule %d to open port %d, but got %+v", i, port, ingress)
+				}
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	code := 4
+	compiled, err := Compile(Spec{
+		Direction:   "ingress",
+		Protocol:    "icmp",
+		Endpoints:   []string{"0.0.0.0/0"},
+		ICMPOptions: &config.ICMPOptionConfig{Type: 3, Code: &code},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(compiled) != 1 {
+		t.Fatalf("Expected 1 rule, but got %d", len(compiled))
+	}
+
+	rule := compiled[0]
+	if rule.Direction != Ingress || rule.Protocol != config.ProtocolICMP {
+		t.Errorf("Expected INGRESS icmp rule, but got %s %s", rule.Direction, rule.Protocol)
+	}
+	if rule.ICMP == nil || rule.ICMP.Type != 3 || *rule.ICMP.Code != 4 {
+		t.Errorf("Expected icmp type 3 code 4, but got %+v", rule.ICMP)
+	}
+
+	if _, err := Compile(Spec{Direction: "sideways", Protocol: "tcp"}); err == nil {
+		t.Errorf("Expected error for invalid direction but got none")
+	}
+}
+
+func TestCompilePortOptions(t *testing.T) {
+	compiled, err := Compile(Spec{
+		Direction: Egress,
+		Protocol:  "udp",
+		Endpoints: []string{"10.0.0.0/16"},
+		UDPOptions: []config.UDPOptionConfig{
+			{MinPort: 53, MaxPort: 53, SourceMinPort: 1024, SourceMaxPort: 65535},
+			{SourceMinPort: 123, SourceMaxPort: 123},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(compiled) != 2 {
+		t.Fatalf("Expected 2 rules, but got %d", len(compiled))
+	}
+
+	first := compiled[0].UDP
+	if first.Destination == nil || first.Destination.Min != 53 || first.Source == nil || first.Source.Max != 65535 {
+		t.Errorf("Expected destination 53 and source 1024-65535, but got %+v", first)
+	}
+	second := compiled[1].UDP
+	if second.Destination != nil || second.Source == nil || second.Source.Min != 123 {
+		t.Errorf("Expected only source port 123, but got %+v", second)
+	}
+}
+
+func TestCompileNSGRule(t *testing.T) {
+	tests := []struct {
+		name             string
+		rule             config.NSGRuleConfig
+		expectedEndpoint string
+		expectedCount    int
+	}{
+		{
+			name: "Ingress uses source",
+			rule: config.NSGRuleConfig{
+				Direction:  "ingress",
+				Protocol:   "6",
+				Source:     "app-nsg",
+				TCPOptions: []config.TCPOptionConfig{{MinPort: 1521, MaxPort: 1521}, {MinPort: 3306, MaxPort: 3306}},
+			},
+			expectedEndpoint: "app-nsg",
+			expectedCount:    2,
+		},
+		{
+			name:             "Egress uses destination",
+			rule:             config.NSGRuleConfig{Direction: "egress", Protocol: "all", Destination: "0.0.0.0/0"},
+			expectedEndpoint: "0.0.0.0/0",
+			expectedCount:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compiled, err := CompileNSGRule(tt.rule)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(compiled) != tt.expectedCount {
+				t.Fatalf("Expected %d rules, but got %d", tt.expectedCount, len(compiled))
+			}
+			for _, rule := range compiled {
+				if rule.Endpoint != tt.expectedEndpoint {
+					t.Errorf("Expected endpoint %s, but got %s", tt.expectedEndpoint, rule.Endpoint)
+				}
+			}
+		})
+	}
+}
+
+func TestCompileGroupedSecurityList(t *testing.T) {
+	seclist := config.SecurityListConfig{
+		DisplayName: "public-subnet-security-list",
+		IngressRules: []config.SecurityRuleConfig{
+			{Protocol: "tcp", Source: "0.0.0.0/0", TCPOptions: []config.TCPOptionConfig{{MinPort: 22, MaxPort: 22}, {MinPort: 443, MaxPort: 443}}},
+			{Protocol: "udp", Sources: []string{"10.0.0.0/16"}, UDPOptions: []config.UDPOptionConfig{{MinPort: 51820, MaxPort: 51820}}},
+		},
+		EgressRules: []config.SecurityRuleConfig{
+			{Protocol: "all", Destination: "0.0.0.0/0"},
+		},
+	}
+
+	compiled, err := CompileSecurityList(seclist)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ingress := Filter(compiled, Ingress)
+	egress := Filter(compiled, Egress)
+	if len(ingress) != 3 || len(egress) != 1 {
+		t.Fatalf("Expected 3 ingress and 1 egress rules, but got %d and %d", len(ingress), len(egress))
+	}
+	if ingress[2].Protocol != config.ProtocolUDP || ingress[2].UDP == nil {
+		t.Errorf("Expected the last ingress rule to be udp, but got %+v", ingress[2])
+	}
+	if egress[0].Protocol != config.ProtocolAll {
+		t.Errorf("Expected egress protocol all, but got %s", egress[0].Protocol)
+	}
+}
@@ -1,64 +1,19 @@
 package network
 
 import (
+	"infra/config"
+	"infra/network/rules"
+
 	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/core"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
 
-// CreateACL Constructor function that creates the Ingress/Egress Security Lists required
-func (n *NetCfg) CreateACL(ctx *pulumi.Context, vcnID string) ([]*core.SecurityList, error) {
+// CreateACL Constructor function that creates the Ingress/Egress Security Lists required.
+// Flat security lists attached to the same subnet are merged into one list, see config.NetworkConfig.GroupedSecurityLists
+func (n *NetCfg) CreateACL(ctx *pulumi.Context, vcnID pulumi.StringInput) ([]*core.SecurityList, error) {
 	var seclists []*core.SecurityList
-	for _, v := range n.SecurityLists {
-		var egressRules core.SecurityListEgressSecurityRuleArray
-		var ingressRules core.SecurityListIngressSecurityRuleArray
-
-		if v.Destination != "" && v.Destination != "null" {
-			egressRule := core.SecurityListEgressSecurityRuleArgs{
-				Protocol:    pulumi.String(v.Protocol),
-				Destination: pulumi.String(v.Destination),
-				Description: pulumi.String(v.Description),
-				Stateless:   pulumi.Bool(v.Stateless),
-			}
-
-			if len(v.TCPOptions) > 0 {
-				for _, tcp := range v.TCPOptions {
-					egressRule.TcpOptions = &core.SecurityListEgressSecurityRuleTcpOptionsArgs{
-						Min: pulumi.Int(tcp.MinPort),
-						Max: pulumi.Int(tcp.MaxPort),
-					}
-				}
-			}
-
-			egressRules = append(egressRules, egressRule)
-		}
-
-		if v.Source != "" && v.Source != "null" {
-			ingressRule := core.SecurityListIngressSecurityRuleArgs{
-				Protocol:    pulumi.String(v.Protocol),
-				Source:      pulumi.String(v.Source),
-				Description: pulumi.String(v.Description),
-				Stateless:   pulumi.Bool(v.Stateless),
-			}
-
-			if len(v.TCPOptions) > 0 {
-				for _, tcp := range v.TCPOptions {
-					ingressRule.TcpOptions = &core.SecurityListIngressSecurityRuleTcpOptionsArgs{
-						Min: pulumi.Int(tcp.MinPort),
-						Max: pulumi.Int(tcp.MaxPort),
-					}
-				}
-			}
-
-			ingressRules = append(ingressRules, ingressRule)
-		}
-
-		sec, err := core.NewSecurityList(ctx, v.DisplayName, &core.SecurityListArgs{
-			CompartmentId:        pulumi.String(n.CompartmentID),
-			VcnId:                pulumi.String(vcnID),
-			DisplayName:          pulumi.String(v.DisplayName),
-			EgressSecurityRules:  egressRules,
-			IngressSecurityRules: ingressRules,
-		})
+	for _, v := range n.GroupedSecurityLists() {
+		sec, err := n.createSecurityList(ctx, vcnID, v)
 		if err != nil {
 			return nil, err
 		}
@@ -69,65 +24,114 @@ func (n *NetCfg) CreateACL(ctx *pulumi.Context, vcnID string) ([]*core.SecurityL
 
 // CreateACLMap creates security lists and returns a map of display names to security list resources
 // This enables security lists to be easily referenced by their display names when attaching to subnets
-func (n *NetCfg) CreateACLMap(ctx *pulumi.Context, vcnID string) (map[string]*core.SecurityList, error) {
+func (n *NetCfg) CreateACLMap(ctx *pulumi.Context, vcnID pulumi.StringInput) (map[string]*core.SecurityList, error) {
 	secListMap := make(map[string]*core.SecurityList)
 
-	for _, v := range n.SecurityLists {
-		var egressRules core.SecurityListEgressSecurityRuleArray
-		var ingressRules core.SecurityListIngressSecurityRuleArray
+	for _, v := range n.GroupedSecurityLists() {
+		sec, err := n.createSecurityList(ctx, vcnID, v)
+		if err != nil {
+			return nil, err
+		}
+		secListMap[v.DisplayName] = sec
+	}
 
-		if v.Destination != "" && v.Destination != "null" {
-			egressRule := core.SecurityListEgressSecurityRuleArgs{
-				Protocol:    pulumi.String(v.Protocol),
-				Destination: pulumi.String(v.Destination),
-				Description: pulumi.String(v.Description),
-				Stateless:   pulumi.Bool(v.Stateless),
-			}
+	return secListMap, nil
+}
 
-			if len(v.TCPOptions) > 0 {
-				for _, tcp := range v.TCPOptions {
-					egressRule.TcpOptions = &core.SecurityListEgressSecurityRuleTcpOptionsArgs{
-						Min: pulumi.Int(tcp.MinPort),
-						Max: pulumi.Int(tcp.MaxPort),
-					}
-				}
-			}
+// createSecurityList compiles the rules of a security list and creates it
+func (n *NetCfg) createSecurityList(ctx *pulumi.Context, vcnID pulumi.StringInput, v config.SecurityListConfig) (*core.SecurityList, error) {
+	compiled, err := rules.CompileSecurityList(v)
+	if err != nil {
+		return nil, err
+	}
 
-			egressRules = append(egressRules, egressRule)
-		}
+	return core.NewSecurityList(ctx, n.resourceName(v.DisplayName), &core.SecurityListArgs{
+		CompartmentId:        n.compartmentID(),
+		VcnId:                vcnID,
+		DisplayName:          pulumi.String(v.DisplayName),
+		EgressSecurityRules:  egressRuleArgs(rules.Filter(compiled, rules.Egress)),
+		IngressSecurityRules: ingressRuleArgs(rules.Filter(compiled, rules.Ingress)),
+	}, n.resourceOptions...)
+}
 
-		if v.Source != "" && v.Source != "null" {
-			ingressRule := core.SecurityListIngressSecurityRuleArgs{
-				Protocol:    pulumi.String(v.Protocol),
-				Source:      pulumi.String(v.Source),
-				Description: pulumi.String(v.Description),
-				Stateless:   pulumi.Bool(v.Stateless),
+// egressRuleArgs converts compiled egress rules into security list rule arguments
+func egressRuleArgs(compiled []rules.Rule) core.SecurityListEgressSecurityRuleArray {
+	var egressRules core.SecurityListEgressSecurityRuleArray
+	for _, rule := range compiled {
+		egressRule := core.SecurityListEgressSecurityRuleArgs{
+			Protocol:    pulumi.String(rule.Protocol),
+			Destination: pulumi.String(rule.Endpoint),
+			Description: pulumi.String(rule.Description),
+			Stateless:   pulumi.Bool(rule.Stateless),
+		}
+		if rule.TCP != nil {
+			tcp := &core.SecurityListEgressSecurityRuleTcpOptionsArgs{}
+			if r := rule.TCP.Destination; r != nil {
+				tcp.Min, tcp.Max = pulumi.Int(r.Min), pulumi.Int(r.Max)
 			}
-
-			if len(v.TCPOptions) > 0 {
-				for _, tcp := range v.TCPOptions {
-					ingressRule.TcpOptions = &core.SecurityListIngressSecurityRuleTcpOptionsArgs{
-						Min: pulumi.Int(tcp.MinPort),
-						Max: pulumi.Int(tcp.MaxPort),
-					}
-				}
+			if r := rule.TCP.Source; r != nil {
+				tcp.SourcePortRange = &core.SecurityListEgressSecurityRuleTcpOptionsSourcePortRangeArgs{Min: pulumi.Int(r.Min), Max: pulumi.Int(r.Max)}
 			}
-
-			ingressRules = append(ingressRules, ingressRule)
+			egressRule.TcpOptions = tcp
 		}
-
-		sec, err := core.NewSecurityList(ctx, v.DisplayName, &core.SecurityListArgs{
-			CompartmentId:        pulumi.String(n.CompartmentID),
-			VcnId:                pulumi.String(vcnID),
-			DisplayName:          pulumi.String(v.DisplayName),
-			EgressSecurityRules:  egressRules,
-			IngressSecurityRules: ingressRules,
-		})
-		if err != nil {
-			return nil, err
+		if rule.UDP != nil {
+			udp := &core.SecurityListEgressSecurityRuleUdpOptionsArgs{}
+			if r := rule.UDP.Destination; r != nil {
+				udp.Min, udp.Max = pulumi.Int(r.Min), pulumi.Int(r.Max)
+			}
+			if r := rule.UDP.Source; r != nil {
+				udp.SourcePortRange = &core.SecurityListEgressSecurityRuleUdpOptionsSourcePortRangeArgs{Min: pulumi.Int(r.Min), Max: pulumi.Int(r.Max)}
+			}
+			egressRule.UdpOptions = udp
 		}
-		secListMap[v.DisplayName] = sec
+		if rule.ICMP != nil {
+			egressRule.IcmpOptions = &core.SecurityListEgressSecurityRuleIcmpOptionsArgs{
+				Type: pulumi.Int(rule.ICMP.Type),
+				Code: pulumi.IntPtrFromPtr(rule.ICMP.Code),
+			}
+		}
+		egressRules = append(egressRules, egressRule)
 	}
+	return egressRules
+}
 
-	return secListMap, nil
+// ingressRuleArgs converts compiled ingress rules into security list rule arguments
+func ingressRuleArgs(compiled []rules.Rule) core.SecurityListIngressSecurityRuleArray {
+	var ingressRules core.SecurityListIngressSecurityRuleArray
+	for _, rule := range compiled {
+		ingressRule := core.SecurityListIngressSecurityRuleArgs{
+			Protocol:    pulumi.String(rule.Protocol),
+			Source:      pulumi.String(rule.Endpoint),
+			Description: pulumi.String(rule.Description),
+			Stateless:   pulumi.Bool(rule.Stateless),
+		}
+		if rule.TCP != nil {
+			tcp := &core.SecurityListIngressSecurityRuleTcpOptionsArgs{}
+			if r := rule.TCP.Destination; r != nil {
+				tcp.Min, tcp.Max = pulumi.Int(r.Min), pulumi.Int(r.Max)
+			}
+			if r := rule.TCP.Source; r != nil {
+				tcp.SourcePortRange = &core.SecurityListIngressSecurityRuleTcpOptionsSourcePortRangeArgs{Min: pulumi.Int(r.Min), Max: pulumi.Int(r.Max)}
+			}
+			ingressRule.TcpOptions = tcp
+		}
+		if rule.UDP != nil {
+			udp := &core.SecurityListIngressSecurityRuleUdpOptionsArgs{}
+			if r := rule.UDP.Destination; r != nil {
+				udp.Min, udp.Max = pulumi.Int(r.Min), pulumi.Int(r.Max)
+			}
+			if r := rule.UDP.Source; r != nil {
+				udp.SourcePortRange = &core.SecurityListIngressSecurityRuleUdpOptionsSourcePortRangeArgs{Min: pulumi.Int(r.Min), Max: pulumi.Int(r.Max)}
+			}
+			ingressRule.UdpOptions = udp
+		}
+		if rule.ICMP != nil {
+			ingressRule.IcmpOptions = &core.SecurityListIngressSecurityRuleIcmpOptionsArgs{
+				Type: pulumi.Int(rule.ICMP.Type),
+				Code: pulumi.IntPtrFromPtr(rule.ICMP.Code),
+			}
+		}
+		ingressRules = append(ingressRules, ingressRule)
+	}
+	return ingressRules
 }
@@ -2,7 +2,9 @@ package network
 
 import (
 	"infra/config"
+	"infra/network/rules"
 
+	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/core"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 	"testing"
@@ -85,7 +87,7 @@ func TestCreateACL(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			err := pulumi.RunErr(func(ctx *pulumi.Context) error {
-				seclists, err := tt.netCfg.CreateACL(ctx, tt.vcnID)
+				seclists, err := tt.netCfg.CreateACL(ctx, pulumi.String(tt.vcnID))
 				if err != nil {
 					return err
 				}
@@ -138,7 +140,7 @@ func TestCreateACLEgressOnly(t *testing.T) {
 	}
 
 	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
-		seclists, err := netCfg.CreateACL(ctx, "vcn-123")
+		seclists, err := netCfg.CreateACL(ctx, pulumi.String("vcn-123"))
 		if err != nil {
 			return err
 		}
@@ -181,7 +183,7 @@ func TestCreateACLIngressOnly(t *testing.T) {
 	}
 
 	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
-		seclists, err := netCfg.CreateACL(ctx, "vcn-123")
+		seclists, err := netCfg.CreateACL(ctx, pulumi.String("vcn-123"))
 		if err != nil {
 			return err
 		}
@@ -224,7 +226,7 @@ func TestCreateACLStatelessRules(t *testing.T) {
 	}
 
 	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
-		seclists, err := netCfg.CreateACL(ctx, "vcn-123")
+		seclists, err := netCfg.CreateACL(ctx, pulumi.String("vcn-123"))
 		if err != nil {
 			return err
 		}
@@ -292,17 +294,17 @@ func TestCreateACLMap(t *testing.T) {
 	}
 
 	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
-		secListMap, err := netCfg.CreateACLMap(ctx, "vcn-123")
+		secListMap, err := netCfg.CreateACLMap(ctx, pulumi.String("vcn-123"))
 		if err != nil {
 			return err
 		}
 
-		if len(secListMap) != 3 {
-			t.Errorf("Expected 3 security lists in map, but got %d", len(secListMap))
+		if len(secListMap) != 2 {
+			t.Errorf("Expected 2 security lists in map, but got %d", len(secListMap))
 		}
 
-		// Verify that each security list exists in the map
-		expectedLists := []string{"public-ingress", "public-egress", "private-ingress"}
+		// Flat security lists are merged into one security list per subnet
+		expectedLists := []string{"public-subnet-security-list", "private-subnet-security-list"}
 		for _, name := range expectedLists {
 			if _, exists := secListMap[name]; !exists {
 				t.Errorf("Expected security list %s to be in map, but it was not found", name)
@@ -322,3 +324,66 @@ func TestCreateACLMap(t *testing.T) {
 		t.Errorf("Unexpected error: %v", err)
 	}
 }
+
+func TestIngressRuleArgs(t *testing.T) {
+	code := 4
+	compiled := []rules.Rule{
+		{
+			Direction: rules.Ingress,
+			Protocol:  "17",
+			Endpoint:  "10.0.0.0/16",
+			UDP: &rules.PortOptions{
+				Destination: &rules.PortRange{Min: 53, Max: 53},
+				Source:      &rules.PortRange{Min: 1024, Max: 65535},
+			},
+		},
+		{
+			Direction: rules.Ingress,
+			Protocol:  "1",
+			Endpoint:  "0.0.0.0/0",
+			ICMP:      &rules.ICMPOptions{Type: 3, Code: &code},
+		},
+		{
+			Direction: rules.Ingress,
+			Protocol:  "6",
+			Endpoint:  "0.0.0.0/0",
+			TCP:       &rules.PortOptions{Destination: &rules.PortRange{Min: 443, Max: 443}},
+		},
+	}
+
+	ingress := ingressRuleArgs(compiled)
+	if len(ingress) != len(compiled) {
+		t.Fatalf("Expected %d ingress rules, but got %d", len(compiled), len(ingress))
+	}
+
+	udp := ingress[0].(core.SecurityListIngressSecurityRuleArgs).UdpOptions.(*core.SecurityListIngressSecurityRuleUdpOptionsArgs)
+	if udp.Min != pulumi.Int(53) || udp.SourcePortRange == nil {
+		t.Errorf("Expected udp port 53 with a source port range, but got %+v", udp)
+	}
+	icmp := ingress[1].(core.SecurityListIngressSecurityRuleArgs).IcmpOptions.(*core.SecurityListIngressSecurityRuleIcmpOptionsArgs)
+	if icmp.Type != pulumi.Int(3) || icmp.Code == nil {
+		t.Errorf("Expected icmp type 3 with a code, but got %+v", icmp)
+	}
+	tcp := ingress[2].(core.SecurityListIngressSecurityRuleArgs).TcpOptions.(*core.SecurityListIngressSecurityRuleTcpOptionsArgs)
+	if tcp.Min != pulumi.Int(443) || tcp.Max != pulumi.Int(443) || tcp.SourcePortRange != nil {
+		t.Errorf("Expected tcp port 443 without a source port range, but got %+v", tcp)
+	}
+}
+
+func TestCreateACLInvalidProtocol(t *testing.T) {
+	netCfg := NetCfg{
+		NetworkConfig: config.NetworkConfig{
+			BaseConfig:    config.BaseConfig{CompartmentID: "compartment-123"},
+			SecurityLists: []config.SecurityListConfig{{DisplayName: "bad", Protocol: "sctp", Source: "0.0.0.0/0"}},
+		},
+	}
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		_, err := netCfg.CreateACL(ctx, pulumi.String("vcn-123"))
+		return err
+	}, pulumi.WithMocks("project", "stack", SecurityListMocks(0)))
+
+	if err == nil {
+		t.Errorf("Expected error but got none")
+	}
+}
@@ -2,25 +2,57 @@ package network
 
 import (
 	"fmt"
+	"infra/config"
 
 	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/core"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
 
 // CreateSubnet creates a subnet within oci using subnet configuration from the Subnets slice
-func (n *NetCfg) CreateSubnet(ctx *pulumi.Context, subnetIndex int, vcnID string, seclists []string) (*core.Subnet, error) {
+func (n *NetCfg) CreateSubnet(ctx *pulumi.Context, subnetIndex int, vcnID pulumi.StringInput, seclists []string) (*core.Subnet, error) {
 	if subnetIndex < 0 || subnetIndex >= len(n.Subnets) {
 		return nil, fmt.Errorf("subnet index %d out of range", subnetIndex)
 	}
 
 	subnet := n.Subnets[subnetIndex]
-	return core.NewSubnet(ctx, subnet.Name, &core.SubnetArgs{
-		CompartmentId:   pulumi.String(n.CompartmentID),
-		CidrBlock:       pulumi.String(subnet.CidrBlock),
-		DisplayName:     pulumi.String(subnet.Name),
-		VcnId:           pulumi.String(vcnID),
-		SecurityListIds: pulumi.ToStringArray(seclists),
-	})
+	args, err := n.subnetArgs(subnet, vcnID)
+	if err != nil {
+		return nil, err
+	}
+	args.SecurityListIds = pulumi.ToStringArray(seclists)
+
+	return core.NewSubnet(ctx, n.resourceName(subnet.Name), args, n.resourceOptions...)
+}
+
+// subnetArgs builds the subnet arguments shared by all subnet constructors from the subnet configuration
+func (n *NetCfg) subnetArgs(subnet config.SubnetConfig, vcnID pulumi.StringInput) (*core.SubnetArgs, error) {
+	routeTableID, err := n.GetRouteTableIDForSubnet(subnet)
+	if err != nil {
+		return nil, err
+	}
+
+	dhcpOptionsID, err := n.GetDHCPOptionsIDForSubnet(subnet)
+	if err != nil {
+		return nil, err
+	}
+
+	args := &core.SubnetArgs{
+		CompartmentId:          n.compartmentID(),
+		CidrBlock:              pulumi.String(subnet.CidrBlock),
+		DisplayName:            pulumi.String(subnet.Name),
+		VcnId:                  vcnID,
+		ProhibitPublicIpOnVnic: pulumi.Bool(!subnet.Public),
+		RouteTableId:           routeTableID,
+		DhcpOptionsId:          dhcpOptionsID,
+	}
+	if subnet.DNSLabel != "" {
+		args.DnsLabel = pulumi.String(subnet.DNSLabel)
+	}
+	if subnet.AvailabilityDomain != "" {
+		args.AvailabilityDomain = pulumi.String(subnet.AvailabilityDomain)
+	}
+
+	return args, nil
 }
 
 // BuildSubnetSecurityListMap creates a mapping of subnet names to security list display names
@@ -28,7 +60,7 @@ func (n *NetCfg) CreateSubnet(ctx *pulumi.Context, subnetIndex int, vcnID string
 func (n *NetCfg) BuildSubnetSecurityListMap() map[string][]string {
 	subnetSecLists := make(map[string][]string)
 
-	for _, slConfig := range n.SecurityLists {
+	for _, slConfig := range n.GroupedSecurityLists() {
 		if slConfig.SubnetName != "" {
 			subnetSecLists[slConfig.SubnetName] = append(subnetSecLists[slConfig.SubnetName], slConfig.DisplayName)
 		}
@@ -65,7 +97,7 @@ func (n *NetCfg) GetSecurityListIDsForSubnet(ctx *pulumi.Context, subnetName str
 
 // CreateSubnetWithSecurityLists creates a subnet and attaches the appropriate security lists
 // based on the subnet name configuration
-func (n *NetCfg) CreateSubnetWithSecurityLists(ctx *pulumi.Context, subnetIndex int, vcnID string, securityListMap map[string]*core.SecurityList) (*core.Subnet, error) {
+func (n *NetCfg) CreateSubnetWithSecurityLists(ctx *pulumi.Context, subnetIndex int, vcnID pulumi.StringInput, securityListMap map[string]*core.SecurityList) (*core.Subnet, error) {
 	if subnetIndex < 0 || subnetIndex >= len(n.Subnets) {
 		return nil, fmt.Errorf("subnet index %d out of range", subnetIndex)
 	}
@@ -73,17 +105,17 @@ func (n *NetCfg) CreateSubnetWithSecurityLists(ctx *pulumi.Context, subnetIndex
 	subnetConfig := n.Subnets[subnetIndex]
 	secListIDs := n.GetSecurityListIDsForSubnet(ctx, subnetConfig.Name, securityListMap)
 
-	return core.NewSubnet(ctx, subnetConfig.Name, &core.SubnetArgs{
-		CompartmentId:   pulumi.String(n.CompartmentID),
-		CidrBlock:       pulumi.String(subnetConfig.CidrBlock),
-		DisplayName:     pulumi.String(subnetConfig.Name),
-		VcnId:           pulumi.String(vcnID),
-		SecurityListIds: secListIDs,
-	})
+	args, err := n.subnetArgs(subnetConfig, vcnID)
+	if err != nil {
+		return nil, err
+	}
+	args.SecurityListIds = secListIDs
+
+	return core.NewSubnet(ctx, n.resourceName(subnetConfig.Name), args, n.resourceOptions...)
 }
 
 // CreateAllSubnets creates all subnets defined in the Subnets slice
-func (n *NetCfg) CreateAllSubnets(ctx *pulumi.Context, vcnID string, seclists []string) ([]*core.Subnet, error) {
+func (n *NetCfg) CreateAllSubnets(ctx *pulumi.Context, vcnID pulumi.StringInput, seclists []string) ([]*core.Subnet, error) {
 	var subnets []*core.Subnet
 
 	for i := range n.Subnets {
@@ -99,7 +131,7 @@ func (n *NetCfg) CreateAllSubnets(ctx *pulumi.Context, vcnID string, seclists []
 
 // CreateAllSubnetsWithSecurityLists creates all subnets with their respective security lists
 // attached based on subnet name configuration
-func (n *NetCfg) CreateAllSubnetsWithSecurityLists(ctx *pulumi.Context, vcnID string, securityListMap map[string]*core.SecurityList) ([]*core.Subnet, error) {
+func (n *NetCfg) CreateAllSubnetsWithSecurityLists(ctx *pulumi.Context, vcnID pulumi.StringInput, securityListMap map[string]*core.SecurityList) ([]*core.Subnet, error) {
 	var subnets []*core.Subnet
 
 	for i := range n.Subnets {
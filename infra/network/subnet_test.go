@@ -102,7 +102,7 @@ func TestCreateSubnet(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			err := pulumi.RunErr(func(ctx *pulumi.Context) error {
 				seclists := []string{"seclist-1", "seclist-2"}
-				subnet, err := tt.netCfg.CreateSubnet(ctx, tt.subnetIndex, tt.vcnID, seclists)
+				subnet, err := tt.netCfg.CreateSubnet(ctx, tt.subnetIndex, pulumi.String(tt.vcnID), seclists)
 				if err != nil {
 					return err
 				}
@@ -139,7 +139,7 @@ func TestCreateSubnetWithEmptyConfig(t *testing.T) {
 
 	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
 		seclists := []string{"seclist-1", "seclist-2"}
-		subnet, err := netCfg.CreateSubnet(ctx, 0, "vcn-123", seclists)
+		subnet, err := netCfg.CreateSubnet(ctx, 0, pulumi.String("vcn-123"), seclists)
 		if err != nil {
 			return err
 		}
@@ -185,7 +185,7 @@ func TestCreateSubnetWithInvalidIndex(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			err := pulumi.RunErr(func(ctx *pulumi.Context) error {
 				seclists := []string{"seclist-1", "seclist-2"}
-				subnet, err := netCfg.CreateSubnet(ctx, tt.subnetIndex, "vcn-123", seclists)
+				subnet, err := netCfg.CreateSubnet(ctx, tt.subnetIndex, pulumi.String("vcn-123"), seclists)
 				if err != nil {
 					return err
 				}
@@ -230,7 +230,7 @@ func TestCreateAllSubnets(t *testing.T) {
 
 	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
 		seclists := []string{"seclist-1", "seclist-2"}
-		subnets, err := netCfg.CreateAllSubnets(ctx, "vcn-123", seclists)
+		subnets, err := netCfg.CreateAllSubnets(ctx, pulumi.String("vcn-123"), seclists)
 		if err != nil {
 			return err
 		}
@@ -268,7 +268,7 @@ func TestCreateAllSubnetsWithEmptySlice(t *testing.T) {
 
 	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
 		seclists := []string{"seclist-1", "seclist-2"}
-		subnets, err := netCfg.CreateAllSubnets(ctx, "vcn-123", seclists)
+		subnets, err := netCfg.CreateAllSubnets(ctx, pulumi.String("vcn-123"), seclists)
 		if err != nil {
 			return err
 		}
@@ -317,6 +317,21 @@ func TestBuildSubnetSecurityListMap(t *testing.T) {
 			expectedCount: 2,
 			expectedLists: []string{"public-ingress", "public-egress"},
 		},
+		{
+			name: "Flat security lists merged per subnet",
+			netCfg: NetCfg{
+				NetworkConfig: config.NetworkConfig{
+					SecurityLists: []config.SecurityListConfig{
+						{DisplayName: "public-ingress", SubnetName: "public-subnet", Protocol: "tcp", Source: "0.0.0.0/0"},
+						{DisplayName: "public-egress", SubnetName: "public-subnet", Protocol: "all", Destination: "0.0.0.0/0"},
+						{DisplayName: "public-extra", SubnetName: "public-subnet"},
+					},
+				},
+			},
+			subnetName:    "public-subnet",
+			expectedCount: 2,
+			expectedLists: []string{"public-subnet-security-list", "public-extra"},
+		},
 		{
 			name: "Security lists without subnet_name",
 			netCfg: NetCfg{
@@ -445,7 +460,7 @@ func TestCreateSubnetWithSecurityLists(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			err := pulumi.RunErr(func(ctx *pulumi.Context) error {
-				subnet, err := tt.netCfg.CreateSubnetWithSecurityLists(ctx, tt.subnetIndex, tt.vcnID, tt.securityListMap)
+				subnet, err := tt.netCfg.CreateSubnetWithSecurityLists(ctx, tt.subnetIndex, pulumi.String(tt.vcnID), tt.securityListMap)
 				if err != nil {
 					return err
 				}
@@ -507,7 +522,7 @@ func TestCreateAllSubnetsWithSecurityLists(t *testing.T) {
 	}
 
 	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
-		subnets, err := netCfg.CreateAllSubnetsWithSecurityLists(ctx, "vcn-123", map[string]*core.SecurityList{})
+		subnets, err := netCfg.CreateAllSubnetsWithSecurityLists(ctx, pulumi.String("vcn-123"), map[string]*core.SecurityList{})
 		if err != nil {
 			return err
 		}
@@ -529,3 +544,48 @@ func TestCreateAllSubnetsWithSecurityLists(t *testing.T) {
 		t.Errorf("Unexpected error creating all subnets with security lists: %v", err)
 	}
 }
+
+func TestCreateAllSubnetsWithVCNOutput(t *testing.T) {
+	netCfg := NetCfg{
+		NetworkConfig: config.NetworkConfig{
+			BaseConfig: config.BaseConfig{
+				CompartmentID: "compartment-123",
+			},
+			CidrBlock:   "10.0.0.0/16",
+			DisplayName: "test-vcn",
+			Subnets: []config.SubnetConfig{
+				{Name: "public-subnet", CidrBlock: "10.0.1.0/24"},
+			},
+		},
+	}
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		vcn, err := netCfg.CreateVCN(ctx, netCfg.DisplayName)
+		if err != nil {
+			return err
+		}
+
+		secListMap, err := netCfg.CreateACLMap(ctx, vcn.ID())
+		if err != nil {
+			return err
+		}
+
+		subnets, err := netCfg.CreateAllSubnetsWithSecurityLists(ctx, vcn.ID(), secListMap)
+		if err != nil {
+			return err
+		}
+
+		pulumi.All(subnets[0].VcnId, vcn.ID()).ApplyT(func(args []interface{}) error {
+			if args[0].(string) != string(args[1].(pulumi.ID)) {
+				t.Errorf("Expected subnet vcn_id %s, but got %s", args[1], args[0])
+			}
+			return nil
+		})
+
+		return nil
+	}, pulumi.WithMocks("project", "stack", SubnetMocks(0)))
+
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
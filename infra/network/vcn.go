@@ -10,15 +10,54 @@ import (
 // NetCfg Struct contains all the needed fields to setup a VCN using pulumi
 type NetCfg struct {
 	config.NetworkConfig
+	// RouteTableMap holds the route tables created by CreateRouteTables keyed by name,
+	// so that subnets can reference them with route_table
+	RouteTableMap map[string]*core.RouteTable
+	// DHCPOptionsMap holds the DHCP options created by CreateDHCPOptions keyed by name,
+	// so that subnets can reference them with dhcp_options
+	DHCPOptionsMap map[string]*core.DhcpOptions
+	// Compartments holds the IDs of the compartments created in this program keyed by name,
+	// so that the network can reference its compartment with compartment
+	Compartments map[string]pulumi.StringInput
+
+	// namePrefix and resourceOptions are set by NewVcn to namespace and parent every child resource
+	namePrefix      string
+	resourceOptions []pulumi.ResourceOption
+}
+
+// resourceName returns the Pulumi resource name of a child resource, prefixed with the component name if any
+func (n *NetCfg) resourceName(name string) string {
+	if n.namePrefix == "" {
+		return name
+	}
+	return n.namePrefix + "-" + name
+}
+
+// compartmentID returns the compartment of every network resource. An unresolved compartment
+// reference is reported by CreateVCN.
+func (n *NetCfg) compartmentID() pulumi.StringInput {
+	id, err := n.CompartmentIDInput(n.Compartments)
+	if err != nil {
+		return pulumi.String(n.CompartmentID)
+	}
+	return id
 }
 
 // CreateVCN creates a vcn within oci
 func (n *NetCfg) CreateVCN(ctx *pulumi.Context, name string) (*core.Vcn, error) {
+	compartmentID, err := n.CompartmentIDInput(n.Compartments)
+	if err != nil {
+		return nil, err
+	}
 
-	return core.NewVcn(ctx, name, &core.VcnArgs{
-		CompartmentId: pulumi.String(n.CompartmentID),
+	args := &core.VcnArgs{
+		CompartmentId: compartmentID,
 		CidrBlock:     pulumi.String(n.CidrBlock),
 		DisplayName:   pulumi.String(n.DisplayName),
-	})
+	}
+	if n.DNSLabel != "" {
+		args.DnsLabel = pulumi.String(n.DNSLabel)
+	}
 
+	return core.NewVcn(ctx, n.resourceName(name), args, n.resourceOptions...)
 }
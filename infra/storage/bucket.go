@@ -0,0 +1,165 @@
+// Package storage creates the object storage buckets described by the storage section of the config
+package storage
+
+import (
+	"fmt"
+	"infra/config"
+
+	"github.com/pulumi/pulumi-oci/sdk/v3/go/oci/objectstorage"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// OutputBuckets is the stack export key of the buckets keyed by config name
+const OutputBuckets = "buckets"
+
+// StorageCfg wraps the StorageConfig and provides methods for managing buckets
+type StorageCfg struct {
+	config.StorageConfig
+	// Compartments resolves compartment references by name, see config.BaseConfig.CompartmentIDInput
+	Compartments map[string]pulumi.StringInput
+}
+
+// Bucket is a created bucket with its optional lifecycle policy
+type Bucket struct {
+	Bucket          *objectstorage.Bucket
+	LifecyclePolicy *objectstorage.ObjectLifecyclePolicy
+}
+
+// namespace returns the configured object storage namespace or looks up the namespace of the tenancy
+func (s *StorageCfg) namespace(ctx *pulumi.Context, compartmentID pulumi.StringInput) pulumi.StringOutput {
+	if s.Namespace != "" {
+		return pulumi.String(s.Namespace).ToStringOutput()
+	}
+	return objectstorage.GetNamespaceOutput(ctx, objectstorage.GetNamespaceOutputArgs{
+		CompartmentId: compartmentID.ToStringOutput().ToStringPtrOutput(),
+	}).Namespace()
+}
+
+// CreateBuckets creates every bucket with its lifecycle policy and returns them keyed by config name
+func (s *StorageCfg) CreateBuckets(ctx *pulumi.Context) (map[string]*Bucket, error) {
+	buckets := make(map[string]*Bucket, len(s.Buckets))
+	if len(s.Buckets) == 0 {
+		return buckets, nil
+	}
+
+	compartmentID, err := s.CompartmentIDInput(s.Compartments)
+	if err != nil {
+		return nil, err
+	}
+	namespace := s.namespace(ctx, compartmentID)
+
+	for _, v := range s.Buckets {
+		if _, exists := buckets[v.Name]; exists {
+			return nil, fmt.Errorf("bucket %s is defined more than once", v.Name)
+		}
+
+		bucket, err := s.createBucket(ctx, v, compartmentID, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create bucket %s: %w", v.Name, err)
+		}
+		buckets[v.Name] = bucket
+	}
+	return buckets, nil
+}
+
+// createBucket creates a bucket and, when it has lifecycle rules, its lifecycle policy
+func (s *StorageCfg) createBucket(ctx *pulumi.Context, v config.BucketConfig, compartmentID pulumi.StringInput, namespace pulumi.StringOutput) (*Bucket, error) {
+	name := s.BucketName(v)
+
+	args := &objectstorage.BucketArgs{
+		CompartmentId:       compartmentID,
+		Namespace:           namespace,
+		Name:                pulumi.String(name),
+		AccessType:          pulumi.String(valueOrDefault(v.AccessType, config.AccessNoPublic)),
+		StorageTier:         pulumi.String(valueOrDefault(v.StorageTier, config.StorageTierStandard)),
+		Versioning:          pulumi.String(valueOrDefault(v.Versioning, config.VersioningDisabled)),
+		ObjectEventsEnabled: pulumi.Bool(v.ObjectEvents),
+		RetentionRules:      retentionRuleArgs(v.RetentionRules),
+	}
+	if v.KMSKeyID != "" {
+		args.KmsKeyId = pulumi.String(v.KMSKeyID)
+	}
+
+	bucket, err := objectstorage.NewBucket(ctx, name, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(v.LifecycleRules) == 0 {
+		return &Bucket{Bucket: bucket}, nil
+	}
+
+	policy, err := objectstorage.NewObjectLifecyclePolicy(ctx, name+"-lifecycle", &objectstorage.ObjectLifecyclePolicyArgs{
+		Bucket:    bucket.Name,
+		Namespace: bucket.Namespace,
+		Rules:     lifecycleRuleArgs(v.LifecycleRules),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lifecycle policy: %w", err)
+	}
+	return &Bucket{Bucket: bucket, LifecyclePolicy: policy}, nil
+}
+
+// retentionRuleArgs converts retention rules, leaving the duration of indefinite rules unset
+func retentionRuleArgs(rules []config.RetentionRuleConfig) objectstorage.BucketRetentionRuleArray {
+	var args objectstorage.BucketRetentionRuleArray
+	for _, rule := range rules {
+		arg := objectstorage.BucketRetentionRuleArgs{
+			DisplayName: pulumi.String(rule.Name),
+		}
+		if rule.Duration != nil {
+			arg.Duration = &objectstorage.BucketRetentionRuleDurationArgs{
+				TimeAmount: pulumi.String(fmt.Sprint(rule.Duration.Amount)),
+				TimeUnit:   pulumi.String(rule.Duration.TimeUnit()),
+			}
+		}
+		if rule.TimeRuleLocked != "" {
+			arg.TimeRuleLocked = pulumi.String(rule.TimeRuleLocked)
+		}
+		args = append(args, arg)
+	}
+	return args
+}
+
+// lifecycleRuleArgs converts lifecycle rules, targeting objects unless another target is set
+func lifecycleRuleArgs(rules []config.LifecycleRuleConfig) objectstorage.ObjectLifecyclePolicyRuleArray {
+	var args objectstorage.ObjectLifecyclePolicyRuleArray
+	for _, rule := range rules {
+		arg := objectstorage.ObjectLifecyclePolicyRuleArgs{
+			Name:       pulumi.String(rule.Name),
+			Action:     pulumi.String(rule.Action),
+			Target:     pulumi.String(valueOrDefault(rule.Target, config.LifecycleTargetObjects)),
+			IsEnabled:  pulumi.Bool(!rule.Disabled),
+			TimeAmount: pulumi.String(fmt.Sprint(rule.After.Amount)),
+			TimeUnit:   pulumi.String(rule.After.TimeUnit()),
+		}
+		if len(rule.InclusionPrefixes) > 0 || len(rule.InclusionPatterns) > 0 || len(rule.ExclusionPatterns) > 0 {
+			arg.ObjectNameFilter = &objectstorage.ObjectLifecyclePolicyRuleObjectNameFilterArgs{
+				InclusionPrefixes: pulumi.ToStringArray(rule.InclusionPrefixes),
+				InclusionPatterns: pulumi.ToStringArray(rule.InclusionPatterns),
+				ExclusionPatterns: pulumi.ToStringArray(rule.ExclusionPatterns),
+			}
+		}
+		args = append(args, arg)
+	}
+	return args
+}
+
+// Outputs returns the OCI name and namespace of every bucket keyed by config name
+func Outputs(buckets map[string]*Bucket) pulumi.Map {
+	outputs := pulumi.Map{}
+	for name, bucket := range buckets {
+		outputs[name] = pulumi.Map{
+			"name":      bucket.Bucket.Name,
+			"namespace": bucket.Bucket.Namespace,
+		}
+	}
+	return outputs
+}
+
+// valueOrDefault returns value, or def when value is empty
+func valueOrDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"infra/config"
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// StorageMocks records the inputs of every created resource by name and answers namespace lookups
+type StorageMocks struct {
+	mu        sync.Mutex
+	resources map[string]resource.PropertyMap
+	calls     int
+}
+
+func (m *StorageMocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	m.mu.Lock()
+	m.resources[args.Name] = args.Inputs
+	m.mu.Unlock()
+	return args.Name + "_id", args.Inputs, nil
+}
+
+func (m *StorageMocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+	m.mu.Lock()
+	m.calls++
+	m.mu.Unlock()
+	return resource.PropertyMap{"namespace": resource.NewStringProperty("looked-up")}, nil
+}
+
+func newStorageMocks() *StorageMocks {
+	return &StorageMocks{resources: make(map[string]resource.PropertyMap)}
+}
+
+func newTestStorageCfg() StorageCfg {
+	return StorageCfg{
+		StorageConfig: config.StorageConfig{
+			BaseConfig: config.BaseConfig{Compartment: "storage"},
+			NamePrefix: "dev",
+			Buckets: []config.BucketConfig{
+				{Name: "artifacts"},
+				{
+					Name:         "logs",
+					AccessType:   config.AccessObjectRead,
+					StorageTier:  config.StorageTierStandard,
+					ObjectEvents: true,
+					KMSKeyID:     "ocid1.key.oc1..example",
+					RetentionRules: []config.RetentionRuleConfig{
+						{Name: "keep-30-days", Duration: &config.DurationConfig{Amount: 30, Unit: "days"}},
+						{Name: "legal-hold"},
+					},
+					LifecycleRules: []config.LifecycleRuleConfig{
+						{Name: "archive", Action: config.LifecycleArchive, After: config.DurationConfig{Amount: 90, Unit: "days"}, InclusionPrefixes: []string{"app/"}},
+						{Name: "abort-uploads", Action: config.LifecycleAbort, Target: config.LifecycleTargetMultipartUploads, After: config.DurationConfig{Amount: 7, Unit: "days"}, Disabled: true},
+					},
+				},
+			},
+		},
+		Compartments: map[string]pulumi.StringInput{"storage": pulumi.String("ocid1.compartment.oc1..storage")},
+	}
+}
+
+func TestCreateBuckets(t *testing.T) {
+	scfg := newTestStorageCfg()
+	mocks := newStorageMocks()
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		buckets, err := scfg.CreateBuckets(ctx)
+		if err != nil {
+			return err
+		}
+		if len(buckets) != 2 || buckets["logs"] == nil {
+			t.Fatalf("Expected buckets keyed by config name, but got %v", buckets)
+		}
+		if buckets["artifacts"].LifecyclePolicy != nil {
+			t.Errorf("Expected no lifecycle policy for a bucket without lifecycle rules")
+		}
+		if buckets["logs"].LifecyclePolicy == nil {
+			t.Errorf("Expected a lifecycle policy for a bucket with lifecycle rules")
+		}
+		if outputs := Outputs(buckets); len(outputs) != 2 {
+			t.Errorf("Expected 2 bucket outputs, but got %d", len(outputs))
+		}
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	artifacts := mocks.resources["dev-artifacts"]
+	expected := map[string]string{
+		"name":          "dev-artifacts",
+		"namespace":     "looked-up",
+		"compartmentId": "ocid1.compartment.oc1..storage",
+		"accessType":    config.AccessNoPublic,
+		"storageTier":   config.StorageTierStandard,
+		"versioning":    config.VersioningDisabled,
+	}
+	for key, value := range expected {
+		if got := artifacts[resource.PropertyKey(key)].StringValue(); got != value {
+			t.Errorf("Expected %s to be %s, but got %s", key, value, got)
+		}
+	}
+
+	logs := mocks.resources["dev-logs"]
+	if !logs["objectEventsEnabled"].BoolValue() {
+		t.Errorf("Expected object events to be enabled")
+	}
+	if got := logs["kmsKeyId"].StringValue(); got != "ocid1.key.oc1..example" {
+		t.Errorf("Expected kms key ocid1.key.oc1..example, but got %s", got)
+	}
+	retention := logs["retentionRules"].ArrayValue()
+	if len(retention) != 2 {
+		t.Fatalf("Expected 2 retention rules, but got %d", len(retention))
+	}
+	duration := retention[0].ObjectValue()["duration"].ObjectValue()
+	if duration["timeAmount"].StringValue() != "30" || duration["timeUnit"].StringValue() != "DAYS" {
+		t.Errorf("Expected a 30 DAYS retention, but got %v", duration)
+	}
+	if _, ok := retention[1].ObjectValue()["duration"]; ok {
+		t.Errorf("Expected an indefinite retention rule without duration")
+	}
+
+	policy, ok := mocks.resources["dev-logs-lifecycle"]
+	if !ok {
+		t.Fatalf("Expected lifecycle policy dev-logs-lifecycle to be created")
+	}
+	if got := policy["bucket"].StringValue(); got != "dev-logs" {
+		t.Errorf("Expected lifecycle policy on dev-logs, but got %s", got)
+	}
+	rules := policy["rules"].ArrayValue()
+	if len(rules) != 2 {
+		t.Fatalf("Expected 2 lifecycle rules, but got %d", len(rules))
+	}
+	archive, abort := rules[0].ObjectValue(), rules[1].ObjectValue()
+	if archive["target"].StringValue() != config.LifecycleTargetObjects || !archive["isEnabled"].BoolValue() {
+		t.Errorf("Expected an enabled rule on objects, but got %v", archive)
+	}
+	if prefixes := archive["objectNameFilter"].ObjectValue()["inclusionPrefixes"].ArrayValue(); len(prefixes) != 1 || prefixes[0].StringValue() != "app/" {
+		t.Errorf("Expected inclusion prefix app/, but got %v", prefixes)
+	}
+	if abort["isEnabled"].BoolValue() {
+		t.Errorf("Expected the abort rule to be disabled")
+	}
+}
+
+func TestCreateBucketsNamespace(t *testing.T) {
+	scfg := newTestStorageCfg()
+	scfg.Namespace = "configured"
+	mocks := newStorageMocks()
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		_, err := scfg.CreateBuckets(ctx)
+		return err
+	}, pulumi.WithMocks("project", "stack", mocks))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if mocks.calls != 0 {
+		t.Errorf("Expected no namespace lookup, but got %d", mocks.calls)
+	}
+	if got := mocks.resources["dev-artifacts"]["namespace"].StringValue(); got != "configured" {
+		t.Errorf("Expected namespace configured, but got %s", got)
+	}
+}
+
+func TestCreateBucketsUnknownCompartment(t *testing.T) {
+	scfg := newTestStorageCfg()
+	scfg.Compartments = nil
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		_, err := scfg.CreateBuckets(ctx)
+		return err
+	}, pulumi.WithMocks("project", "stack", newStorageMocks()))
+
+	if err == nil {
+		t.Errorf("Expected error but got none")
+	}
+}